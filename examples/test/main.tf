@@ -37,6 +37,11 @@ resource "rgw_user" "test_with_quota" {
   }
 }
 
+# Additional key pair, rotate with `terraform apply -replace=rgw_user_key.test`
+resource "rgw_user_key" "test" {
+  user_id = rgw_user.test.id
+}
+
 resource "rgw_bucket" "test" {
   name = "test"
 }
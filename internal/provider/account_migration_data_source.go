@@ -0,0 +1,269 @@
+package provider
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"sort"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = &AccountMigrationDataSource{}
+
+func NewAccountMigrationDataSource() datasource.DataSource {
+	return &AccountMigrationDataSource{}
+}
+
+type AccountMigrationDataSource struct {
+	client *RgwClient
+}
+
+type AccountMigrationDataSourceModel struct {
+	Id           types.String                `tfsdk:"id"`
+	Tenant       types.String                `tfsdk:"tenant"`
+	Users        []AccountMigrationUserModel `tfsdk:"users"`
+	Roles        []types.String              `tfsdk:"roles"`
+	PendingUsers []types.String              `tfsdk:"pending_users"`
+	BucketCount  types.Int64                 `tfsdk:"bucket_count"`
+	KeyCount     types.Int64                 `tfsdk:"key_count"`
+}
+
+type AccountMigrationUserModel struct {
+	Id         types.String   `tfsdk:"id"`
+	AccountId  types.String   `tfsdk:"account_id"`
+	Suspended  types.Bool     `tfsdk:"suspended"`
+	Buckets    []types.String `tfsdk:"buckets"`
+	AccessKeys []types.String `tfsdk:"access_keys"`
+	Subusers   []types.String `tfsdk:"subusers"`
+}
+
+func (d *AccountMigrationDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_account_migration"
+}
+
+func (d *AccountMigrationDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Report of everything of a legacy tenant which needs to be migrated into an RGW account: its users with their buckets, keys and subusers, and its roles. Users already in an account are reported with their `account_id`, so the report tracks the progress of a phased migration.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "The tenant to migrate. Use `\"\"` for the default tenant.",
+				Required:            true,
+			},
+			"users": schema.ListNestedAttribute{
+				MarkdownDescription: "The users of the tenant, sorted by ID",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "The full user ID (`tenant$username` or `username`)",
+							Computed:            true,
+						},
+						"account_id": schema.StringAttribute{
+							MarkdownDescription: "The account the user belongs to, empty if not migrated yet",
+							Computed:            true,
+						},
+						"suspended": schema.BoolAttribute{
+							MarkdownDescription: "Whether the user is suspended, e.g. to be removed instead of migrated",
+							Computed:            true,
+						},
+						"buckets": schema.ListAttribute{
+							MarkdownDescription: "The buckets owned by the user, qualified with the tenant",
+							ElementType:         types.StringType,
+							Computed:            true,
+						},
+						"access_keys": schema.ListAttribute{
+							MarkdownDescription: "The S3 access keys of the user, without secrets",
+							ElementType:         types.StringType,
+							Computed:            true,
+						},
+						"subusers": schema.ListAttribute{
+							MarkdownDescription: "The full IDs of the Swift subusers of the user",
+							ElementType:         types.StringType,
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"roles": schema.ListAttribute{
+				MarkdownDescription: "The names of the roles of the tenant. Roles can only be listed for the tenant of the provider credentials, otherwise the list is empty and a warning is reported.",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"pending_users": schema.ListAttribute{
+				MarkdownDescription: "The IDs of the users not in an account yet",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"bucket_count": schema.Int64Attribute{
+				MarkdownDescription: "Number of buckets of all users",
+				Computed:            true,
+			},
+			"key_count": schema.Int64Attribute{
+				MarkdownDescription: "Number of S3 access keys of all users",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *AccountMigrationDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "data.rgw_account_migration")...)
+}
+
+func (d *AccountMigrationDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *AccountMigrationDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// accounts only exist from Squid on
+	resp.Diagnostics.Append(d.client.requireFeature(featureAccounts)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tenant := data.Tenant.ValueString()
+
+	users, err := d.client.Admin.GetUsers(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("could not list users", err.Error())
+		return
+	}
+
+	data.Users = []AccountMigrationUserModel{}
+	data.PendingUsers = []types.String{}
+	bucketCount, keyCount := 0, 0
+	if users != nil {
+		sort.Strings(*users)
+		for _, userId := range *users {
+			if t, _ := tenantOfUser(userId); t != tenant {
+				continue
+			}
+
+			user, err := d.client.Admin.GetUser(ctx, admin.User{ID: userId})
+			if err != nil {
+				resp.Diagnostics.AddError("could not get user", err.Error())
+				return
+			}
+			accountId, err := d.client.accountOfUser(ctx, userId)
+			if err != nil {
+				resp.Diagnostics.AddError("could not get account of user", err.Error())
+				return
+			}
+			buckets, err := d.client.listUserBuckets(ctx, userId)
+			if err != nil {
+				resp.Diagnostics.AddError("could not list buckets of user", err.Error())
+				return
+			}
+			sort.Strings(buckets)
+
+			u := AccountMigrationUserModel{
+				Id:         types.StringValue(userId),
+				AccountId:  types.StringValue(accountId),
+				Suspended:  types.BoolValue(user.Suspended != nil && *user.Suspended != 0),
+				Buckets:    []types.String{},
+				AccessKeys: []types.String{},
+				Subusers:   []types.String{},
+			}
+			for _, b := range buckets {
+				u.Buckets = append(u.Buckets, types.StringValue(b))
+			}
+			for _, k := range user.Keys {
+				// the keys of subusers are listed with the user
+				if k.User == userId || k.User == "" {
+					u.AccessKeys = append(u.AccessKeys, types.StringValue(k.AccessKey))
+				}
+			}
+			for _, s := range user.Subusers {
+				u.Subusers = append(u.Subusers, types.StringValue(s.Name))
+			}
+
+			data.Users = append(data.Users, u)
+			if accountId == "" {
+				data.PendingUsers = append(data.PendingUsers, u.Id)
+			}
+			bucketCount += len(u.Buckets)
+			keyCount += len(u.AccessKeys)
+		}
+	}
+
+	// roles can only be listed in the tenant of the provider credentials
+	data.Roles = []types.String{}
+	identity, err := d.client.getAdminIdentity(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("could not get tenant of the provider credentials", err.Error())
+		return
+	}
+	if t, _ := tenantOfUser(identity.UserId); t == tenant {
+		roles, err := d.client.listRoles(ctx)
+		if err != nil {
+			resp.Diagnostics.AddError("could not list roles", err.Error())
+			return
+		}
+		for _, r := range roles {
+			data.Roles = append(data.Roles, types.StringValue(r))
+		}
+	} else {
+		resp.Diagnostics.AddWarning("roles not listed",
+			fmt.Sprintf("The roles of tenant '%s' can't be listed with provider credentials of tenant '%s', list them with: radosgw-admin role list --tenant=%s", tenant, t, tenant))
+	}
+
+	data.Id = types.StringValue(tenant)
+	data.BucketCount = types.Int64Value(int64(bucketCount))
+	data.KeyCount = types.Int64Value(int64(keyCount))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// listRoles lists the names of the roles of the tenant of the provider credentials
+func (c *RgwClient) listRoles(ctx context.Context) ([]string, error) {
+	body, err := c.iamCall(ctx, "ListRoles", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var roles []iamRole
+	if err := xml.Unmarshal(body, &struct {
+		Roles *[]iamRole `xml:"ListRolesResult>Roles>member"`
+	}{&roles}); err != nil {
+		return nil, fmt.Errorf("could not decode roles: %w", err)
+	}
+
+	names := make([]string, len(roles))
+	for i, r := range roles {
+		names[i] = r.RoleName
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,25 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestListRoles(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<ListRolesResponse><ListRolesResult><Roles>` +
+			`<member><RoleName>uploader</RoleName><Path>/</Path></member>` +
+			`<member><RoleName>ci</RoleName><Path>/</Path></member>` +
+			`</Roles></ListRolesResult></ListRolesResponse>`))
+	})
+
+	roles, err := client.listRoles(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(roles, []string{"ci", "uploader"}) {
+		t.Errorf("unexpected roles %v", roles)
+	}
+}
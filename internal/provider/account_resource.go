@@ -0,0 +1,338 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &AccountResource{}
+var _ resource.ResourceWithModifyPlan = &AccountResource{}
+var _ resource.ResourceWithImportState = &AccountResource{}
+
+func NewAccountResource() resource.Resource {
+	return &AccountResource{}
+}
+
+type AccountResource struct {
+	client *RgwClient
+}
+
+type AccountResourceModel struct {
+	Id         types.String `tfsdk:"id"`
+	AccountId  types.String `tfsdk:"account_id"`
+	Name       types.String `tfsdk:"name"`
+	Email      types.String `tfsdk:"email"`
+	Tenant     types.String `tfsdk:"tenant"`
+	MaxUsers   types.Int64  `tfsdk:"max_users"`
+	MaxRoles   types.Int64  `tfsdk:"max_roles"`
+	MaxBuckets types.Int64  `tfsdk:"max_buckets"`
+}
+
+func (r *AccountResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_account"
+}
+
+func (r *AccountResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	limit := func(description string) schema.Int64Attribute {
+		return schema.Int64Attribute{
+			MarkdownDescription: description + ". A negative value disables the limit. Defaults to the limit of RGW, `1000`.",
+			Optional:            true,
+			Computed:            true,
+			PlanModifiers: []planmodifier.Int64{
+				int64planmodifier.UseStateForUnknown(),
+			},
+			Validators: []validator.Int64{
+				int64validator.AtLeast(-1),
+			},
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "RGW account, owning users, roles and buckets like an AWS account. Requires Ceph >= 19.2 (Squid). An account can only be deleted without users, roles and buckets.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"account_id": schema.StringAttribute{
+				MarkdownDescription: "The account ID, `RGW` followed by 17 digits. Generated by RGW if not set.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+					stringplanmodifier.UseStateForUnknown(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^RGW[0-9]{17}$`), "must be RGW followed by 17 digits"),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The account name, unique per tenant",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"email": schema.StringAttribute{
+				MarkdownDescription: "The email address associated with the account",
+				Optional:            true,
+			},
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "The tenant of the account",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"max_users":   limit("Maximum number of users of the account"),
+			"max_roles":   limit("Maximum number of roles of the account"),
+			"max_buckets": limit("Maximum number of buckets of the account"),
+		},
+	}
+}
+
+func (r *AccountResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_account")...)
+}
+
+func (r *AccountResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "tenant", func(tenant string) (string, bool) { return tenant, true })...)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.client.requireFeature(featureAccounts)...)
+}
+
+func (r *AccountResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create account")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *AccountResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	args := accountArgs(data)
+	if !data.AccountId.IsUnknown() {
+		args.Set("id", data.AccountId.ValueString())
+	}
+	if !data.Tenant.IsNull() {
+		args.Set("tenant", data.Tenant.ValueString())
+	}
+
+	body, err := r.client.adminCall(ctx, http.MethodPost, "/account", args, nil)
+	if err != nil {
+		resp.Diagnostics.AddError("could not create account", err.Error())
+		return
+	}
+
+	account := rgwAccount{}
+	if err := json.Unmarshal(body, &account); err != nil {
+		resp.Diagnostics.AddError("could not decode account", err.Error())
+		return
+	}
+	account.toModel(data)
+	data.Id = data.AccountId
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccountResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *AccountResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	account, err := r.client.getAccount(ctx, data.Id.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get account", err.Error())
+		return
+	}
+	account.toModel(data)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_account", req.State, resp.State)...)
+}
+
+func (r *AccountResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update account")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *AccountResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	args := accountArgs(data)
+	args.Set("id", data.AccountId.ValueString())
+
+	body, err := r.client.adminCall(ctx, http.MethodPut, "/account", args, nil)
+	if err != nil {
+		resp.Diagnostics.AddError("could not modify account", err.Error())
+		return
+	}
+
+	account := rgwAccount{}
+	if err := json.Unmarshal(body, &account); err != nil {
+		resp.Diagnostics.AddError("could not decode account", err.Error())
+		return
+	}
+	account.toModel(data)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccountResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete account")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *AccountResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.adminCall(ctx, http.MethodDelete, "/account", url.Values{"id": []string{data.Id.ValueString()}}, nil)
+	if err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("could not delete account", err.Error())
+		return
+	}
+}
+
+func (r *AccountResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// rgwAccount is an account as returned by the account admin api
+type rgwAccount struct {
+	Id         string `json:"id"`
+	Tenant     string `json:"tenant"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	MaxUsers   int64  `json:"max_users"`
+	MaxRoles   int64  `json:"max_roles"`
+	MaxBuckets int64  `json:"max_buckets"`
+}
+
+// toModel sets the attributes of the resource from the account, keeping
+// unset optional attributes null
+func (a *rgwAccount) toModel(data *AccountResourceModel) {
+	data.AccountId = types.StringValue(a.Id)
+	data.Name = types.StringValue(a.Name)
+	if !data.Email.IsNull() || a.Email != "" {
+		data.Email = types.StringValue(a.Email)
+	}
+	if !data.Tenant.IsNull() || a.Tenant != "" {
+		data.Tenant = types.StringValue(a.Tenant)
+	}
+	data.MaxUsers = types.Int64Value(a.MaxUsers)
+	data.MaxRoles = types.Int64Value(a.MaxRoles)
+	data.MaxBuckets = types.Int64Value(a.MaxBuckets)
+}
+
+// accountArgs returns the arguments of the account api for the attributes
+// which can be set on create and modify
+func accountArgs(data *AccountResourceModel) url.Values {
+	args := url.Values{
+		"name": []string{data.Name.ValueString()},
+	}
+	if !data.Email.IsNull() {
+		args.Set("email", data.Email.ValueString())
+	}
+	for name, limit := range map[string]types.Int64{
+		"max-users":   data.MaxUsers,
+		"max-roles":   data.MaxRoles,
+		"max-buckets": data.MaxBuckets,
+	} {
+		if !limit.IsUnknown() && !limit.IsNull() {
+			args.Set(name, strconv.FormatInt(limit.ValueInt64(), 10))
+		}
+	}
+	return args
+}
+
+// getAccount gets an account by its ID
+func (c *RgwClient) getAccount(ctx context.Context, accountId string) (*rgwAccount, error) {
+	body, err := c.adminCall(ctx, http.MethodGet, "/account", url.Values{"id": []string{accountId}}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &rgwAccount{}
+	if err := json.Unmarshal(body, account); err != nil {
+		return nil, fmt.Errorf("could not decode account: %w", err)
+	}
+
+	return account, nil
+}
@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestAccountModel(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/admin/account" || r.URL.Query().Get("id") != "RGW12345678901234567" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		_, _ = w.Write([]byte(`{"id":"RGW12345678901234567","tenant":"","name":"payments","email":"","max_users":1000,"max_roles":50,"max_buckets":-1}`))
+	})
+
+	account, err := client.getAccount(context.Background(), "RGW12345678901234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := &AccountResourceModel{MaxUsers: types.Int64Unknown()}
+	account.toModel(data)
+	if !data.Email.IsNull() || !data.Tenant.IsNull() {
+		t.Errorf("expected unset email and tenant to stay null, got %s and %s", data.Email, data.Tenant)
+	}
+	if data.MaxUsers.ValueInt64() != 1000 || data.MaxRoles.ValueInt64() != 50 || data.MaxBuckets.ValueInt64() != -1 {
+		t.Errorf("unexpected limits %+v", data)
+	}
+
+	args := accountArgs(&AccountResourceModel{
+		Name:       types.StringValue("payments"),
+		MaxUsers:   types.Int64Unknown(),
+		MaxRoles:   types.Int64Value(50),
+		MaxBuckets: types.Int64Null(),
+	})
+	if args.Get("name") != "payments" || args.Has("email") || args.Has("max-users") || args.Get("max-roles") != "50" || args.Has("max-buckets") {
+		t.Errorf("unexpected args %v", args)
+	}
+}
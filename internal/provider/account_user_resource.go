@@ -0,0 +1,228 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &AccountUserResource{}
+var _ resource.ResourceWithModifyPlan = &AccountUserResource{}
+var _ resource.ResourceWithImportState = &AccountUserResource{}
+
+func NewAccountUserResource() resource.Resource {
+	return &AccountUserResource{}
+}
+
+type AccountUserResource struct {
+	client *RgwClient
+}
+
+type AccountUserResourceModel struct {
+	Id        types.String `tfsdk:"id"`
+	UserId    types.String `tfsdk:"user_id"`
+	AccountId types.String `tfsdk:"account_id"`
+}
+
+func (r *AccountUserResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_account_user"
+}
+
+func (r *AccountUserResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Membership of an existing user in an account, adopting a legacy user into the account. Requires Ceph >= 19.2 (Squid). RGW can't move users out of accounts, so on destroy the membership is only removed from the Terraform state.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"user_id": schema.StringAttribute{
+				MarkdownDescription: "The full user ID (`tenant$username` or `username`). The user has to belong to the tenant of the account.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"account_id": schema.StringAttribute{
+				MarkdownDescription: "The ID of the account, e.g. `rgw_account.account_id`",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^RGW[0-9]{17}$`), "must be RGW followed by 17 digits"),
+				},
+			},
+		},
+	}
+}
+
+func (r *AccountUserResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_account_user")...)
+}
+
+func (r *AccountUserResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and user_id against user_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "user_id", tenantOfUser)...)
+	resp.Diagnostics.Append(r.client.planUserPrefix(ctx, req, "user_id")...)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.client.requireFeature(featureAccounts)...)
+
+	// protected users like multisite system users must stay outside of accounts
+	var userId types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("user_id"), &userId)...)
+	if !userId.IsUnknown() && r.client.isProtectedUid(userId.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("user_id"), "user is protected",
+			fmt.Sprintf("The user '%s' is in protected_uids of the provider and must not be moved into an account.", userId.ValueString()))
+	}
+}
+
+func (r *AccountUserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("add user to account")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *AccountUserResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.adminCall(ctx, http.MethodPost, "/user", url.Values{
+		"uid":        []string{data.UserId.ValueString()},
+		"account-id": []string{data.AccountId.ValueString()},
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError("could not add user to account", err.Error())
+		return
+	}
+
+	data.Id = data.UserId
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccountUserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *AccountUserResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	exists, err := r.client.userExists(ctx, data.Id.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("could not get user", err.Error())
+		return
+	}
+	if !exists {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	accountId, err := r.client.accountOfUser(ctx, data.Id.ValueString())
+	if err != nil && !errors.Is(err, admin.ErrNoSuchUser) {
+		resp.Diagnostics.AddError("could not get account of user", err.Error())
+		return
+	}
+
+	data.UserId = data.Id
+	data.AccountId = types.StringValue(accountId)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_account_user", req.State, resp.State)...)
+}
+
+func (r *AccountUserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update account user")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *AccountUserResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Currently there is nothing to update in place, all attributes require replacement
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccountUserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("remove user from account")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *AccountUserResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// users can't leave accounts, keep the user in the account
+	resp.Diagnostics.AddWarning("user stays in account",
+		fmt.Sprintf("RGW can't move users out of accounts, the user '%s' stays in the account '%s'. Only the membership was removed from the Terraform state.", data.UserId.ValueString(), data.AccountId.ValueString()))
+}
+
+func (r *AccountUserResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
@@ -0,0 +1,372 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// adminError is returned by adminCall for non successful responses. It can be
+// compared to the go-ceph admin errors using errors.Is.
+type adminError struct {
+	StatusCode int
+	Code       string `json:"Code"`
+	Message    string `json:"Message"`
+	RequestId  string `json:"RequestId"`
+
+	// RequiredCap is the admin cap the endpoint requires if access was denied
+	RequiredCap string `json:"-"`
+}
+
+func (e adminError) Error() string {
+	msg := fmt.Sprintf("%d %s %s", e.StatusCode, e.Code, e.RequestId)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.RequiredCap != "" {
+		msg += fmt.Sprintf(": the provider credentials probably lack the admin cap '%s'. Grant it with: radosgw-admin caps add --uid=<admin user> --caps='%s'", e.RequiredCap, e.RequiredCap)
+	}
+	return msg
+}
+
+func (e adminError) Is(target error) bool {
+	return target.Error() == e.Code
+}
+
+// adminCall sends a signed request to an admin ops api endpoint not covered by go-ceph
+func (c *RgwClient) adminCall(ctx context.Context, method string, path string, args url.Values, body []byte) ([]byte, error) {
+	if args == nil {
+		args = url.Values{}
+	}
+	args.Set("format", "json")
+
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	request, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/admin%s%s%s", c.Admin.Endpoint, path, sep, args.Encode()), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	signer := v4.NewSigner(credentials.NewStaticCredentials(c.Admin.AccessKey, c.Admin.SecretKey, ""))
+	_, err = signer.Sign(request, bytes.NewReader(body), "s3", "default", time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Admin.HTTPClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		apiErr := adminError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(respBody, &apiErr); err != nil {
+			apiErr.Code = strings.TrimSpace(string(respBody))
+		}
+		return nil, apiErr
+	}
+
+	return respBody, nil
+}
+
+// userMetadata is the user entry of the metadata api
+type userMetadata struct {
+	Key   string `json:"key"`
+	Mtime string `json:"mtime"`
+	Data  struct {
+		CreateDate string `json:"create_date"`
+	} `json:"data"`
+}
+
+// getUserMetadata gets the metadata entry of a user
+func (c *RgwClient) getUserMetadata(ctx context.Context, userId string) (*userMetadata, error) {
+	body, err := c.adminCall(ctx, http.MethodGet, "/metadata/user", url.Values{"key": []string{userId}}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &userMetadata{}
+	if err := json.Unmarshal(body, meta); err != nil {
+		return nil, fmt.Errorf("could not decode user metadata: %w", err)
+	}
+
+	return meta, nil
+}
+
+// userExists checks whether a user exists using the metadata api, which is
+// cheaper than getting the full user info including all keys
+func (c *RgwClient) userExists(ctx context.Context, userId string) (bool, error) {
+	_, err := c.adminCall(ctx, http.MethodGet, "/metadata/user", url.Values{"key": []string{userId}}, nil)
+	if errors.Is(err, admin.ErrNoSuchKey) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// listUserBuckets lists the buckets owned by a user, qualified with the
+// tenant of the user
+func (c *RgwClient) listUserBuckets(ctx context.Context, userId string) ([]string, error) {
+	body, err := c.adminCall(ctx, http.MethodGet, "/bucket", url.Values{"uid": []string{userId}}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []string
+	if err := json.Unmarshal(body, &buckets); err != nil {
+		return nil, fmt.Errorf("could not decode buckets of user: %w", err)
+	}
+
+	if i := strings.Index(userId, "$"); i >= 0 {
+		for j := range buckets {
+			buckets[j] = userId[:i] + "/" + buckets[j]
+		}
+	}
+
+	return buckets, nil
+}
+
+// accountOfUser returns the account the user belongs to, empty if none. Users
+// removed since the usage was logged belong to no account.
+func (c *RgwClient) accountOfUser(ctx context.Context, userId string) (string, error) {
+	meta, err := c.getMetadata(ctx, "user", userId)
+	if errors.Is(err, admin.ErrNoSuchKey) || errors.Is(err, admin.ErrNoSuchUser) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var accountId string
+	if raw, ok := meta.Data["account_id"]; ok {
+		if err := json.Unmarshal(raw, &accountId); err != nil {
+			return "", fmt.Errorf("could not decode account_id of user %s: %w", userId, err)
+		}
+	}
+
+	return accountId, nil
+}
+
+// metadataEntry is a raw entry of the metadata api, as used by `radosgw-admin metadata get/put`
+type metadataEntry struct {
+	Key   string                     `json:"key"`
+	Ver   json.RawMessage            `json:"ver"`
+	Mtime string                     `json:"mtime"`
+	Data  map[string]json.RawMessage `json:"data"`
+}
+
+// getMetadata gets a raw metadata entry of a section (user, bucket, ...)
+func (c *RgwClient) getMetadata(ctx context.Context, section string, key string) (*metadataEntry, error) {
+	body, err := c.adminCall(ctx, http.MethodGet, "/metadata/"+section, url.Values{"key": []string{key}}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &metadataEntry{}
+	if err := json.Unmarshal(body, entry); err != nil {
+		return nil, fmt.Errorf("could not decode %s metadata: %w", section, err)
+	}
+
+	return entry, nil
+}
+
+// putMetadata writes a raw metadata entry of a section (user, bucket, ...)
+func (c *RgwClient) putMetadata(ctx context.Context, section string, key string, entry *metadataEntry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.adminCall(ctx, http.MethodPut, "/metadata/"+section, url.Values{"key": []string{key}}, body)
+	return err
+}
+
+// canonicalJSON returns a canonical encoding of a json document for comparison
+func canonicalJSON(raw []byte) (string, error) {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// quotaInfo is a quota as encoded by rgw
+type quotaInfo struct {
+	Enabled    bool   `json:"enabled"`
+	CheckOnRaw bool   `json:"check_on_raw"`
+	MaxSize    *int64 `json:"max_size"`
+	MaxSizeKb  *int64 `json:"max_size_kb"`
+	MaxObjects int64  `json:"max_objects"`
+}
+
+// sizeKb returns the maximum size in KB, -1 if unlimited. Older releases
+// only encode max_size_kb, newer ones the size in bytes as well.
+func (q quotaInfo) sizeKb() int64 {
+	if q.MaxSize != nil {
+		if *q.MaxSize < 0 {
+			return -1
+		}
+		return *q.MaxSize / 1024
+	}
+	if q.MaxSizeKb != nil {
+		return *q.MaxSizeKb
+	}
+	return -1
+}
+
+// periodConfig is the configuration shared by all zones of the current period
+type periodConfig struct {
+	BucketQuota quotaInfo `json:"bucket_quota"`
+	UserQuota   quotaInfo `json:"user_quota"`
+}
+
+// getPeriodConfig gets the configuration of the current period
+func (c *RgwClient) getPeriodConfig(ctx context.Context) (*periodConfig, error) {
+	body, err := c.adminCall(ctx, http.MethodGet, "/realm/period", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	period := struct {
+		Id           string       `json:"id"`
+		PeriodConfig periodConfig `json:"period_config"`
+	}{}
+	if err := json.Unmarshal(body, &period); err != nil {
+		return nil, fmt.Errorf("could not decode period: %w", err)
+	}
+
+	return &period.PeriodConfig, nil
+}
+
+// setQuota sets user or bucket quota
+func (c *RgwClient) setQuota(ctx context.Context, userId string, quotaType string, quota *UserQuotaModel) error {
+	// go-ceph always sends quota-type=user, call the admin api directly
+	args := url.Values{
+		"uid":         []string{userId},
+		"quota-type":  []string{quotaType},
+		"enabled":     []string{strconv.FormatBool(quota.Enabled.ValueBool())},
+		"max-size-kb": []string{strconv.FormatInt(quota.MaxSizeKb.ValueInt64(), 10)},
+		"max-objects": []string{strconv.FormatInt(quota.MaxObjects.ValueInt64(), 10)},
+	}
+
+	_, err := c.adminCall(ctx, http.MethodPut, "/user?quota", args, nil)
+	return err
+}
+
+// quotaVerifyInterval is the delay between reads verifying a quota
+var quotaVerifyInterval = time.Second
+
+// verifyQuota reads a quota until applied reports it as set or
+// quota_verify_timeout passes, in which case a warning is returned. Without
+// timeout nothing is verified.
+func (c *RgwClient) verifyQuota(ctx context.Context, what string, applied func(ctx context.Context) (bool, error)) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if c.QuotaVerifyTimeout <= 0 {
+		return diags
+	}
+
+	deadline := time.Now().Add(c.QuotaVerifyTimeout)
+	for {
+		ok, err := applied(ctx)
+		if err == nil && ok {
+			return diags
+		}
+		if time.Now().Add(quotaVerifyInterval).After(deadline) {
+			detail := fmt.Sprintf("The %s is not visible %s after setting it. The cluster may still enforce the previous quota until its quota cache is refreshed (rgw_bucket_quota_ttl, rgw_user_quota_sync_interval).", what, c.QuotaVerifyTimeout)
+			if err != nil {
+				detail += fmt.Sprintf(" Last read failed: %s", err.Error())
+			}
+			diags.AddWarning("quota not applied yet", detail)
+			return diags
+		}
+
+		select {
+		case <-ctx.Done():
+			return diags
+		case <-time.After(quotaVerifyInterval):
+		}
+	}
+}
+
+// verifyUserQuota verifies that a user or bucket quota set with setQuota is visible
+func (c *RgwClient) verifyUserQuota(ctx context.Context, userId string, quotaType string, quota *UserQuotaModel) diag.Diagnostics {
+	return c.verifyQuota(ctx, fmt.Sprintf("%s quota of user '%s'", quotaType, userId), func(ctx context.Context) (bool, error) {
+		current, err := c.getQuota(ctx, userId, quotaType)
+		if err != nil {
+			return false, err
+		}
+		return current.Enabled.ValueBool() == quota.Enabled.ValueBool() &&
+			current.MaxSizeKb.ValueInt64() == quota.MaxSizeKb.ValueInt64() &&
+			current.MaxObjects.ValueInt64() == quota.MaxObjects.ValueInt64(), nil
+	})
+}
+
+// getQuota gets user or bucket quota
+func (c *RgwClient) getQuota(ctx context.Context, userId string, quotaType string) (*UserQuotaModel, error) {
+	args := url.Values{
+		"uid":        []string{userId},
+		"quota-type": []string{quotaType},
+	}
+
+	body, err := c.adminCall(ctx, http.MethodGet, "/user?quota", args, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	quota := admin.QuotaSpec{}
+	if err := json.Unmarshal(body, &quota); err != nil {
+		return nil, fmt.Errorf("could not decode %s quota: %w", quotaType, err)
+	}
+
+	model := &UserQuotaModel{}
+	if quota.Enabled != nil {
+		model.Enabled = types.BoolValue(*quota.Enabled)
+	} else {
+		model.Enabled = types.BoolValue(false)
+	}
+
+	if quota.MaxSizeKb != nil {
+		model.MaxSizeKb = types.Int64Value(int64(*quota.MaxSizeKb))
+	} else {
+		model.MaxSizeKb = types.Int64Value(-1)
+	}
+
+	if quota.MaxObjects != nil {
+		model.MaxObjects = types.Int64Value(*quota.MaxObjects)
+	} else {
+		model.MaxObjects = types.Int64Value(-1)
+	}
+
+	return model, nil
+}
@@ -0,0 +1,150 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// newTestClient returns a client for an admin api served by handler
+func newTestClient(t *testing.T, handler http.HandlerFunc) *RgwClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	api, err := admin.New(server.URL, "access", "secret", server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api.HTTPClient = &capsHintClient{next: api.HTTPClient}
+
+	return &RgwClient{Admin: api}
+}
+
+func TestSetQuotaBucketType(t *testing.T) {
+	var query url.Values
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/admin/user" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		query = r.URL.Query()
+	})
+
+	err := client.setQuota(context.Background(), "alice", "bucket", &UserQuotaModel{
+		Enabled:    types.BoolValue(true),
+		MaxSizeKb:  types.Int64Value(1024),
+		MaxObjects: types.Int64Value(-1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := query["quota"]; !ok {
+		t.Errorf("expected quota parameter, got %v", query)
+	}
+	expected := map[string]string{
+		"uid":         "alice",
+		"quota-type":  "bucket",
+		"enabled":     "true",
+		"max-size-kb": "1024",
+		"max-objects": "-1",
+	}
+	for k, v := range expected {
+		if query.Get(k) != v {
+			t.Errorf("expected %s=%s, got %s=%s", k, v, k, query.Get(k))
+		}
+	}
+}
+
+func TestGetQuotaBucketType(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/admin/user" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("quota-type") != "bucket" {
+			t.Errorf("expected quota-type=bucket, got %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"enabled":true,"check_on_raw":false,"max_size":1048576,"max_size_kb":1024,"max_objects":100}`))
+	})
+
+	quota, err := client.getQuota(context.Background(), "alice", "bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !quota.Enabled.ValueBool() || quota.MaxSizeKb.ValueInt64() != 1024 || quota.MaxObjects.ValueInt64() != 100 {
+		t.Errorf("unexpected quota %+v", quota)
+	}
+}
+
+func TestVerifyUserQuota(t *testing.T) {
+	interval := quotaVerifyInterval
+	quotaVerifyInterval = time.Millisecond
+	t.Cleanup(func() { quotaVerifyInterval = interval })
+
+	reads := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		reads++
+		// the new quota shows up on the third read
+		if reads < 3 {
+			_, _ = w.Write([]byte(`{"enabled":false,"max_size_kb":-1,"max_objects":-1}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"enabled":true,"max_size_kb":1024,"max_objects":-1}`))
+	})
+	client.QuotaVerifyTimeout = time.Second
+
+	quota := &UserQuotaModel{
+		Enabled:    types.BoolValue(true),
+		MaxSizeKb:  types.Int64Value(1024),
+		MaxObjects: types.Int64Value(-1),
+	}
+	if diags := client.verifyUserQuota(context.Background(), "alice", "user", quota); diags.WarningsCount() != 0 {
+		t.Errorf("expected no warning, got %v", diags)
+	}
+	if reads != 3 {
+		t.Errorf("expected 3 reads, got %d", reads)
+	}
+
+	// warn if the quota does not show up in time
+	client.QuotaVerifyTimeout = 10 * time.Millisecond
+	quota.MaxObjects = types.Int64Value(100)
+	if diags := client.verifyUserQuota(context.Background(), "alice", "user", quota); diags.WarningsCount() != 1 {
+		t.Errorf("expected a warning, got %v", diags)
+	}
+}
+
+func TestAccessDeniedRequiredCap(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"Code":"AccessDenied","RequestId":"tx1","HostId":"host"}`))
+	})
+
+	// endpoints covered by go-ceph
+	_, err := client.Admin.GetUser(context.Background(), admin.User{ID: "alice"})
+	if !errors.Is(err, admin.ErrAccessDenied) {
+		t.Fatalf("expected AccessDenied, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "'users=read'") {
+		t.Errorf("expected required cap users=read in error, got %s", err)
+	}
+
+	// endpoints called directly
+	_, err = client.adminCall(context.Background(), http.MethodPut, "/bucket?quota", nil, nil)
+	if !errors.Is(err, admin.ErrAccessDenied) {
+		t.Fatalf("expected AccessDenied, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "'buckets=write'") {
+		t.Errorf("expected required cap buckets=write in error, got %s", err)
+	}
+}
@@ -0,0 +1,343 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/smithy-go"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &BucketCorsResource{}
+var _ resource.ResourceWithModifyPlan = &BucketCorsResource{}
+var _ resource.ResourceWithImportState = &BucketCorsResource{}
+
+func NewBucketCorsResource() resource.Resource {
+	return &BucketCorsResource{}
+}
+
+type BucketCorsResource struct {
+	client *RgwClient
+}
+
+type BucketCorsResourceModel struct {
+	Id     types.String          `tfsdk:"id"`
+	Bucket types.String          `tfsdk:"bucket"`
+	Rules  []BucketCorsRuleModel `tfsdk:"rules"`
+}
+
+type BucketCorsRuleModel struct {
+	Id             types.String   `tfsdk:"id"`
+	AllowedOrigins []types.String `tfsdk:"allowed_origins"`
+	AllowedMethods []types.String `tfsdk:"allowed_methods"`
+	AllowedHeaders []types.String `tfsdk:"allowed_headers"`
+	ExposeHeaders  []types.String `tfsdk:"expose_headers"`
+	MaxAgeSeconds  types.Int64    `tfsdk:"max_age_seconds"`
+}
+
+func (r *BucketCorsResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_cors"
+}
+
+func (r *BucketCorsResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "CORS configuration of a bucket. The resource manages the complete configuration, rules not configured here are removed.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Bucket Name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rules": schema.ListNestedAttribute{
+				MarkdownDescription: "CORS rules",
+				Required:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Unique ID of the rule",
+							Optional:            true,
+						},
+						"allowed_origins": schema.ListAttribute{
+							MarkdownDescription: "Origins allowed to access the bucket, e.g. `https://app.example.com`. A single `*` wildcard is allowed per origin.",
+							Required:            true,
+							ElementType:         types.StringType,
+							Validators: []validator.List{
+								listvalidator.SizeAtLeast(1),
+							},
+						},
+						"allowed_methods": schema.ListAttribute{
+							MarkdownDescription: "HTTP methods allowed for the origins: `GET`, `PUT`, `HEAD`, `POST` or `DELETE`",
+							Required:            true,
+							ElementType:         types.StringType,
+							Validators: []validator.List{
+								listvalidator.SizeAtLeast(1),
+								listvalidator.ValueStringsAre(stringvalidator.OneOf("GET", "PUT", "HEAD", "POST", "DELETE")),
+							},
+						},
+						"allowed_headers": schema.ListAttribute{
+							MarkdownDescription: "Headers allowed in preflight requests",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"expose_headers": schema.ListAttribute{
+							MarkdownDescription: "Response headers accessible by the client application",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"max_age_seconds": schema.Int64Attribute{
+							MarkdownDescription: "Time in seconds the browser may cache the preflight response",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *BucketCorsResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_bucket_cors")...)
+}
+
+func (r *BucketCorsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and bucket against bucket_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "bucket", tenantOfBucket)...)
+	resp.Diagnostics.Append(r.client.planBucketPrefix(ctx, req, "bucket")...)
+}
+
+func (r *BucketCorsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create bucket cors")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketCorsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.S3.PutBucketCors(ctx, data.putInput())
+	if err != nil {
+		resp.Diagnostics.AddError("could not create bucket cors configuration", err.Error())
+		return
+	}
+
+	// use bucket name as resource id
+	data.Id = data.Bucket
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketCorsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *BucketCorsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	s3res, err := r.client.S3.GetBucketCors(ctx, &s3.GetBucketCorsInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && (ae.ErrorCode() == "NoSuchCORSConfiguration" || ae.ErrorCode() == "NoSuchBucket") {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get bucket cors configuration", err.Error())
+		return
+	}
+
+	data.Rules = make([]BucketCorsRuleModel, len(s3res.CORSRules))
+	for i, rule := range s3res.CORSRules {
+		data.Rules[i] = bucketCorsRuleFromApi(rule)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_bucket_cors", req.State, resp.State)...)
+}
+
+func (r *BucketCorsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update bucket cors")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketCorsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the configuration is always replaced as a whole
+	_, err := r.client.S3.PutBucketCors(ctx, data.putInput())
+	if err != nil {
+		resp.Diagnostics.AddError("could not modify bucket cors configuration", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketCorsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete bucket cors")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *BucketCorsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.S3.DeleteBucketCors(ctx, &s3.DeleteBucketCorsInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && ae.ErrorCode() == "NoSuchBucket" {
+			return
+		}
+		resp.Diagnostics.AddError("could not delete bucket cors configuration", err.Error())
+		return
+	}
+}
+
+func (r *BucketCorsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("bucket"), req.ID)...)
+}
+
+// putInput converts the model into a PutBucketCors request
+func (m *BucketCorsResourceModel) putInput() *s3.PutBucketCorsInput {
+	rules := make([]s3types.CORSRule, len(m.Rules))
+	for i, rule := range m.Rules {
+		rules[i] = s3types.CORSRule{
+			AllowedOrigins: stringsFromModel(rule.AllowedOrigins),
+			AllowedMethods: stringsFromModel(rule.AllowedMethods),
+			AllowedHeaders: stringsFromModel(rule.AllowedHeaders),
+			ExposeHeaders:  stringsFromModel(rule.ExposeHeaders),
+			MaxAgeSeconds:  int32(rule.MaxAgeSeconds.ValueInt64()),
+		}
+		if !rule.Id.IsNull() {
+			rules[i].ID = aws.String(rule.Id.ValueString())
+		}
+	}
+
+	return &s3.PutBucketCorsInput{
+		Bucket:            aws.String(m.Bucket.ValueString()),
+		CORSConfiguration: &s3types.CORSConfiguration{CORSRules: rules},
+	}
+}
+
+// bucketCorsRuleFromApi converts a cors rule returned by rgw into the model
+func bucketCorsRuleFromApi(rule s3types.CORSRule) BucketCorsRuleModel {
+	model := BucketCorsRuleModel{
+		Id:             types.StringNull(),
+		AllowedOrigins: stringsToModel(rule.AllowedOrigins),
+		AllowedMethods: stringsToModel(rule.AllowedMethods),
+		AllowedHeaders: stringsToModel(rule.AllowedHeaders),
+		ExposeHeaders:  stringsToModel(rule.ExposeHeaders),
+		MaxAgeSeconds:  types.Int64Null(),
+	}
+	if rule.ID != nil && *rule.ID != "" {
+		model.Id = types.StringValue(*rule.ID)
+	}
+	if rule.MaxAgeSeconds > 0 {
+		model.MaxAgeSeconds = types.Int64Value(int64(rule.MaxAgeSeconds))
+	}
+
+	return model
+}
+
+// stringsFromModel converts a list attribute of strings into a slice
+func stringsFromModel(values []types.String) []string {
+	if values == nil {
+		return nil
+	}
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = v.ValueString()
+	}
+	return result
+}
+
+// stringsToModel converts a slice into a list attribute of strings, empty
+// slices become null
+func stringsToModel(values []string) []types.String {
+	if len(values) == 0 {
+		return nil
+	}
+	result := make([]types.String, len(values))
+	for i, v := range values {
+		result[i] = types.StringValue(v)
+	}
+	return result
+}
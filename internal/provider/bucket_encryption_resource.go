@@ -0,0 +1,274 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/smithy-go"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &BucketEncryptionResource{}
+var _ resource.ResourceWithModifyPlan = &BucketEncryptionResource{}
+var _ resource.ResourceWithImportState = &BucketEncryptionResource{}
+
+func NewBucketEncryptionResource() resource.Resource {
+	return &BucketEncryptionResource{}
+}
+
+type BucketEncryptionResource struct {
+	client *RgwClient
+}
+
+type BucketEncryptionResourceModel struct {
+	Id             types.String `tfsdk:"id"`
+	Bucket         types.String `tfsdk:"bucket"`
+	SseAlgorithm   types.String `tfsdk:"sse_algorithm"`
+	KmsMasterKeyId types.String `tfsdk:"kms_master_key_id"`
+}
+
+func (r *BucketEncryptionResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_encryption"
+}
+
+func (r *BucketEncryptionResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Default server-side encryption of new objects in a bucket. Requires Ceph >= 17.2 (Quincy) and a configured key management backend (`rgw_crypt_sse_s3_backend` or `rgw_crypt_s3_kms_backend`, e.g. Vault or Barbican).",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Bucket Name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"sse_algorithm": schema.StringAttribute{
+				MarkdownDescription: "Encryption of new objects: `AES256` for SSE-S3 or `aws:kms` for SSE-KMS",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(string(s3types.ServerSideEncryptionAes256), string(s3types.ServerSideEncryptionAwsKms)),
+				},
+			},
+			"kms_master_key_id": schema.StringAttribute{
+				MarkdownDescription: "ID of the key in the key management backend, e.g. the Vault key name or Barbican secret ID. Only used with `aws:kms`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+		},
+	}
+}
+
+func (r *BucketEncryptionResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_bucket_encryption")...)
+}
+
+func (r *BucketEncryptionResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and bucket against bucket_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "bucket", tenantOfBucket)...)
+	resp.Diagnostics.Append(r.client.planBucketPrefix(ctx, req, "bucket")...)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.client.requireFeature(featureBucketEncryption)...)
+
+	// a key id is meaningless for SSE-S3, rgw would silently ignore it
+	var data *BucketEncryptionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if data.SseAlgorithm.ValueString() == string(s3types.ServerSideEncryptionAes256) && !data.KmsMasterKeyId.IsNull() && !data.KmsMasterKeyId.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(path.Root("kms_master_key_id"), "kms_master_key_id not allowed",
+			"kms_master_key_id can only be set with sse_algorithm 'aws:kms'")
+	}
+}
+
+func (r *BucketEncryptionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create bucket encryption")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketEncryptionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.S3.PutBucketEncryption(ctx, data.putInput())
+	if err != nil {
+		resp.Diagnostics.AddError("could not set bucket encryption", err.Error())
+		return
+	}
+
+	// use bucket name as resource id
+	data.Id = data.Bucket
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketEncryptionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *BucketEncryptionResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	s3res, err := r.client.S3.GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && (ae.ErrorCode() == "ServerSideEncryptionConfigurationNotFoundError" || ae.ErrorCode() == "NoSuchBucket") {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get bucket encryption", err.Error())
+		return
+	}
+
+	// rgw supports a single rule only
+	config := s3res.ServerSideEncryptionConfiguration
+	if config == nil || len(config.Rules) == 0 || config.Rules[0].ApplyServerSideEncryptionByDefault == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	sse := config.Rules[0].ApplyServerSideEncryptionByDefault
+	data.SseAlgorithm = types.StringValue(string(sse.SSEAlgorithm))
+	data.KmsMasterKeyId = types.StringNull()
+	if sse.KMSMasterKeyID != nil && *sse.KMSMasterKeyID != "" {
+		data.KmsMasterKeyId = types.StringValue(*sse.KMSMasterKeyID)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_bucket_encryption", req.State, resp.State)...)
+}
+
+func (r *BucketEncryptionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update bucket encryption")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketEncryptionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.S3.PutBucketEncryption(ctx, data.putInput())
+	if err != nil {
+		resp.Diagnostics.AddError("could not set bucket encryption", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketEncryptionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete bucket encryption")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *BucketEncryptionResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// objects already written stay encrypted
+	_, err := r.client.S3.DeleteBucketEncryption(ctx, &s3.DeleteBucketEncryptionInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && ae.ErrorCode() == "NoSuchBucket" {
+			return
+		}
+		resp.Diagnostics.AddError("could not delete bucket encryption", err.Error())
+		return
+	}
+}
+
+func (r *BucketEncryptionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("bucket"), req.ID)...)
+}
+
+// putInput converts the model into a PutBucketEncryption request
+func (m *BucketEncryptionResourceModel) putInput() *s3.PutBucketEncryptionInput {
+	sse := &s3types.ServerSideEncryptionByDefault{
+		SSEAlgorithm: s3types.ServerSideEncryption(m.SseAlgorithm.ValueString()),
+	}
+	if !m.KmsMasterKeyId.IsNull() {
+		sse.KMSMasterKeyID = aws.String(m.KmsMasterKeyId.ValueString())
+	}
+
+	return &s3.PutBucketEncryptionInput{
+		Bucket: aws.String(m.Bucket.ValueString()),
+		ServerSideEncryptionConfiguration: &s3types.ServerSideEncryptionConfiguration{
+			Rules: []s3types.ServerSideEncryptionRule{
+				{ApplyServerSideEncryptionByDefault: sse},
+			},
+		},
+	}
+}
@@ -0,0 +1,381 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/smithy-go"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &BucketLifecycleConfigurationResource{}
+var _ resource.ResourceWithModifyPlan = &BucketLifecycleConfigurationResource{}
+var _ resource.ResourceWithImportState = &BucketLifecycleConfigurationResource{}
+
+func NewBucketLifecycleConfigurationResource() resource.Resource {
+	return &BucketLifecycleConfigurationResource{}
+}
+
+type BucketLifecycleConfigurationResource struct {
+	client *RgwClient
+}
+
+type BucketLifecycleConfigurationResourceModel struct {
+	Id     types.String               `tfsdk:"id"`
+	Bucket types.String               `tfsdk:"bucket"`
+	Rules  []BucketLifecycleRuleModel `tfsdk:"rules"`
+}
+
+type BucketLifecycleRuleModel struct {
+	Id                                 types.String                     `tfsdk:"id"`
+	Enabled                            types.Bool                       `tfsdk:"enabled"`
+	Prefix                             types.String                     `tfsdk:"prefix"`
+	ExpirationDays                     types.Int64                      `tfsdk:"expiration_days"`
+	NoncurrentVersionExpirationDays    types.Int64                      `tfsdk:"noncurrent_version_expiration_days"`
+	AbortIncompleteMultipartUploadDays types.Int64                      `tfsdk:"abort_incomplete_multipart_upload_days"`
+	Transitions                        []BucketLifecycleTransitionModel `tfsdk:"transitions"`
+}
+
+type BucketLifecycleTransitionModel struct {
+	Days         types.Int64  `tfsdk:"days"`
+	StorageClass types.String `tfsdk:"storage_class"`
+}
+
+func (r *BucketLifecycleConfigurationResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_lifecycle_configuration"
+}
+
+func (r *BucketLifecycleConfigurationResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lifecycle configuration of a bucket. The resource manages the complete configuration, rules not configured here are removed.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Bucket Name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rules": schema.ListNestedAttribute{
+				MarkdownDescription: "Lifecycle rules",
+				Required:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Unique ID of the rule",
+							Required:            true,
+						},
+						"enabled": schema.BoolAttribute{
+							MarkdownDescription: "Whether the rule is applied (default `true`)",
+							Optional:            true,
+							Computed:            true,
+							PlanModifiers: []planmodifier.Bool{
+								boolDefaultModifier{true},
+								boolplanmodifier.UseStateForUnknown(),
+							},
+						},
+						"prefix": schema.StringAttribute{
+							MarkdownDescription: "Only apply the rule to objects with this key prefix. Applies to all objects if not set.",
+							Optional:            true,
+							Computed:            true,
+							PlanModifiers: []planmodifier.String{
+								stringDefaultModifier{""},
+								stringplanmodifier.UseStateForUnknown(),
+							},
+						},
+						"expiration_days": schema.Int64Attribute{
+							MarkdownDescription: "Expire current object versions after this number of days",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+						"noncurrent_version_expiration_days": schema.Int64Attribute{
+							MarkdownDescription: "Expire noncurrent object versions this number of days after they became noncurrent",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+						"abort_incomplete_multipart_upload_days": schema.Int64Attribute{
+							MarkdownDescription: "Abort incomplete multipart uploads this number of days after they were initiated",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+						"transitions": schema.ListNestedAttribute{
+							MarkdownDescription: "Transitions of current object versions to other storage classes",
+							Optional:            true,
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"days": schema.Int64Attribute{
+										MarkdownDescription: "Transition objects after this number of days",
+										Required:            true,
+										Validators: []validator.Int64{
+											int64validator.AtLeast(1),
+										},
+									},
+									"storage_class": schema.StringAttribute{
+										MarkdownDescription: "The storage class of the zonegroup placement target to transition to",
+										Required:            true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *BucketLifecycleConfigurationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_bucket_lifecycle_configuration")...)
+}
+
+func (r *BucketLifecycleConfigurationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and bucket against bucket_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "bucket", tenantOfBucket)...)
+	resp.Diagnostics.Append(r.client.planBucketPrefix(ctx, req, "bucket")...)
+}
+
+func (r *BucketLifecycleConfigurationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create bucket lifecycle configuration")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketLifecycleConfigurationResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.S3.PutBucketLifecycleConfiguration(ctx, data.putInput())
+	if err != nil {
+		resp.Diagnostics.AddError("could not create bucket lifecycle configuration", err.Error())
+		return
+	}
+
+	// use bucket name as resource id
+	data.Id = data.Bucket
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketLifecycleConfigurationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *BucketLifecycleConfigurationResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	s3res, err := r.client.S3.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && (ae.ErrorCode() == "NoSuchLifecycleConfiguration" || ae.ErrorCode() == "NoSuchBucket") {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get bucket lifecycle configuration", err.Error())
+		return
+	}
+
+	data.Rules = make([]BucketLifecycleRuleModel, len(s3res.Rules))
+	for i, rule := range s3res.Rules {
+		data.Rules[i] = bucketLifecycleRuleFromApi(rule)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_bucket_lifecycle_configuration", req.State, resp.State)...)
+}
+
+func (r *BucketLifecycleConfigurationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update bucket lifecycle configuration")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketLifecycleConfigurationResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the configuration is always replaced as a whole
+	_, err := r.client.S3.PutBucketLifecycleConfiguration(ctx, data.putInput())
+	if err != nil {
+		resp.Diagnostics.AddError("could not modify bucket lifecycle configuration", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketLifecycleConfigurationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete bucket lifecycle configuration")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *BucketLifecycleConfigurationResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.S3.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && ae.ErrorCode() == "NoSuchBucket" {
+			return
+		}
+		resp.Diagnostics.AddError("could not delete bucket lifecycle configuration", err.Error())
+		return
+	}
+}
+
+func (r *BucketLifecycleConfigurationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("bucket"), req.ID)...)
+}
+
+// putInput converts the model into a PutBucketLifecycleConfiguration request
+func (m *BucketLifecycleConfigurationResourceModel) putInput() *s3.PutBucketLifecycleConfigurationInput {
+	rules := make([]s3types.LifecycleRule, len(m.Rules))
+	for i, rule := range m.Rules {
+		status := s3types.ExpirationStatusEnabled
+		if !rule.Enabled.ValueBool() {
+			status = s3types.ExpirationStatusDisabled
+		}
+
+		rules[i] = s3types.LifecycleRule{
+			ID:     aws.String(rule.Id.ValueString()),
+			Status: status,
+			Filter: &s3types.LifecycleRuleFilterMemberPrefix{Value: rule.Prefix.ValueString()},
+		}
+		if !rule.ExpirationDays.IsNull() {
+			rules[i].Expiration = &s3types.LifecycleExpiration{Days: int32(rule.ExpirationDays.ValueInt64())}
+		}
+		if !rule.NoncurrentVersionExpirationDays.IsNull() {
+			rules[i].NoncurrentVersionExpiration = &s3types.NoncurrentVersionExpiration{NoncurrentDays: int32(rule.NoncurrentVersionExpirationDays.ValueInt64())}
+		}
+		if !rule.AbortIncompleteMultipartUploadDays.IsNull() {
+			rules[i].AbortIncompleteMultipartUpload = &s3types.AbortIncompleteMultipartUpload{DaysAfterInitiation: int32(rule.AbortIncompleteMultipartUploadDays.ValueInt64())}
+		}
+		for _, t := range rule.Transitions {
+			rules[i].Transitions = append(rules[i].Transitions, s3types.Transition{
+				Days:         int32(t.Days.ValueInt64()),
+				StorageClass: s3types.TransitionStorageClass(t.StorageClass.ValueString()),
+			})
+		}
+	}
+
+	return &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(m.Bucket.ValueString()),
+		LifecycleConfiguration: &s3types.BucketLifecycleConfiguration{Rules: rules},
+	}
+}
+
+// bucketLifecycleRuleFromApi converts a lifecycle rule returned by rgw into the model
+func bucketLifecycleRuleFromApi(rule s3types.LifecycleRule) BucketLifecycleRuleModel {
+	model := BucketLifecycleRuleModel{
+		Id:                                 types.StringValue(aws.StringValue(rule.ID)),
+		Enabled:                            types.BoolValue(rule.Status == s3types.ExpirationStatusEnabled),
+		Prefix:                             types.StringValue(aws.StringValue(rule.Prefix)),
+		ExpirationDays:                     types.Int64Null(),
+		NoncurrentVersionExpirationDays:    types.Int64Null(),
+		AbortIncompleteMultipartUploadDays: types.Int64Null(),
+	}
+
+	// rules written by other tools may use the legacy prefix or an and operator
+	switch filter := rule.Filter.(type) {
+	case *s3types.LifecycleRuleFilterMemberPrefix:
+		model.Prefix = types.StringValue(filter.Value)
+	case *s3types.LifecycleRuleFilterMemberAnd:
+		model.Prefix = types.StringValue(aws.StringValue(filter.Value.Prefix))
+	}
+
+	if rule.Expiration != nil && rule.Expiration.Days > 0 {
+		model.ExpirationDays = types.Int64Value(int64(rule.Expiration.Days))
+	}
+	if rule.NoncurrentVersionExpiration != nil && rule.NoncurrentVersionExpiration.NoncurrentDays > 0 {
+		model.NoncurrentVersionExpirationDays = types.Int64Value(int64(rule.NoncurrentVersionExpiration.NoncurrentDays))
+	}
+	if rule.AbortIncompleteMultipartUpload != nil && rule.AbortIncompleteMultipartUpload.DaysAfterInitiation > 0 {
+		model.AbortIncompleteMultipartUploadDays = types.Int64Value(int64(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation))
+	}
+	for _, t := range rule.Transitions {
+		model.Transitions = append(model.Transitions, BucketLifecycleTransitionModel{
+			Days:         types.Int64Value(int64(t.Days)),
+			StorageClass: types.StringValue(string(t.StorageClass)),
+		})
+	}
+
+	return model
+}
@@ -0,0 +1,247 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &BucketLinkResource{}
+var _ resource.ResourceWithModifyPlan = &BucketLinkResource{}
+var _ resource.ResourceWithImportState = &BucketLinkResource{}
+
+func NewBucketLinkResource() resource.Resource {
+	return &BucketLinkResource{}
+}
+
+type BucketLinkResource struct {
+	client *RgwClient
+}
+
+type BucketLinkResourceModel struct {
+	Id            types.String `tfsdk:"id"`
+	Bucket        types.String `tfsdk:"bucket"`
+	UserId        types.String `tfsdk:"user_id"`
+	BucketId      types.String `tfsdk:"bucket_id"`
+	PreviousOwner types.String `tfsdk:"previous_owner"`
+}
+
+func (r *BucketLinkResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_link"
+}
+
+func (r *BucketLinkResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Links an existing bucket to a user, transferring its ownership, e.g. to hand a bucket to a service account. On destroy the bucket is unlinked from the user, but not linked back to its previous owner.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Bucket Name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"user_id": schema.StringAttribute{
+				MarkdownDescription: "The full user ID (`tenant$username` or `username`) of the new owner. Changing it links the bucket to the other user in place.",
+				Required:            true,
+			},
+			"bucket_id": schema.StringAttribute{
+				MarkdownDescription: "The internal ID of the bucket",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"previous_owner": schema.StringAttribute{
+				MarkdownDescription: "The user ID of the owner before the bucket was linked, e.g. to link it back manually",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *BucketLinkResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_bucket_link")...)
+}
+
+func (r *BucketLinkResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenants against allowed_tenants, bucket against bucket_prefix and user_id against user_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "bucket", tenantOfBucket)...)
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "user_id", tenantOfUser)...)
+	resp.Diagnostics.Append(r.client.planBucketPrefix(ctx, req, "bucket")...)
+	resp.Diagnostics.Append(r.client.planUserPrefix(ctx, req, "user_id")...)
+}
+
+func (r *BucketLinkResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create bucket link")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketLinkResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// remember the owner before the link, the api needs the bucket id
+	bucket, err := r.client.Admin.GetBucketInfo(ctx, admin.Bucket{Bucket: data.Bucket.ValueString()})
+	if err != nil {
+		resp.Diagnostics.AddError("could not get bucket info", err.Error())
+		return
+	}
+	data.BucketId = types.StringValue(bucket.ID)
+	data.PreviousOwner = types.StringValue(bucket.Owner)
+
+	err = r.linkBucket(ctx, data)
+	if err != nil {
+		resp.Diagnostics.AddError("could not link bucket", err.Error())
+		return
+	}
+
+	data.Id = data.Bucket
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketLinkResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *BucketLinkResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	bucket, err := r.client.Admin.GetBucketInfo(ctx, admin.Bucket{Bucket: data.Bucket.ValueString()})
+	if err != nil {
+		if errors.Is(err, admin.ErrNoSuchBucket) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get bucket info", err.Error())
+		return
+	}
+
+	data.UserId = types.StringValue(bucket.Owner)
+	data.BucketId = types.StringValue(bucket.ID)
+
+	// the owner before the link is unknown after import
+	if data.PreviousOwner.IsNull() {
+		data.PreviousOwner = types.StringValue(bucket.Owner)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_bucket_link", req.State, resp.State)...)
+}
+
+func (r *BucketLinkResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update bucket link")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketLinkResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// linking to another user unlinks the bucket from the current one
+	err := r.linkBucket(ctx, data)
+	if err != nil {
+		resp.Diagnostics.AddError("could not link bucket", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketLinkResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete bucket link")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *BucketLinkResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.Admin.UnlinkBucket(ctx, admin.BucketLinkInput{
+		Bucket: data.Bucket.ValueString(),
+		UID:    data.UserId.ValueString(),
+	})
+	if err != nil && !errors.Is(err, admin.ErrNoSuchBucket) && !errors.Is(err, admin.ErrNoSuchUser) {
+		resp.Diagnostics.AddError("could not unlink bucket", err.Error())
+		return
+	}
+}
+
+func (r *BucketLinkResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("bucket"), req.ID)...)
+}
+
+// linkBucket links the bucket to the user, unlinking it from its current owner
+func (r *BucketLinkResource) linkBucket(ctx context.Context, data *BucketLinkResourceModel) error {
+	return r.client.Admin.LinkBucket(ctx, admin.BucketLinkInput{
+		Bucket:   data.Bucket.ValueString(),
+		BucketID: data.BucketId.ValueString(),
+		UID:      data.UserId.ValueString(),
+	})
+}
@@ -0,0 +1,273 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/smithy-go"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &BucketObjectLockConfigurationResource{}
+var _ resource.ResourceWithModifyPlan = &BucketObjectLockConfigurationResource{}
+var _ resource.ResourceWithImportState = &BucketObjectLockConfigurationResource{}
+
+func NewBucketObjectLockConfigurationResource() resource.Resource {
+	return &BucketObjectLockConfigurationResource{}
+}
+
+type BucketObjectLockConfigurationResource struct {
+	client *RgwClient
+}
+
+type BucketObjectLockConfigurationResourceModel struct {
+	Id     types.String `tfsdk:"id"`
+	Bucket types.String `tfsdk:"bucket"`
+	Mode   types.String `tfsdk:"mode"`
+	Days   types.Int64  `tfsdk:"days"`
+	Years  types.Int64  `tfsdk:"years"`
+}
+
+func (r *BucketObjectLockConfigurationResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_object_lock_configuration"
+}
+
+func (r *BucketObjectLockConfigurationResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Default retention of new objects in a bucket created with object lock enabled. Object lock itself can't be disabled, so on destroy only the default retention is removed.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Bucket Name, the bucket must have been created with object lock enabled",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"mode": schema.StringAttribute{
+				MarkdownDescription: "Retention mode of new objects: `GOVERNANCE` or `COMPLIANCE`",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(string(s3types.ObjectLockRetentionModeGovernance), string(s3types.ObjectLockRetentionModeCompliance)),
+				},
+			},
+			"days": schema.Int64Attribute{
+				MarkdownDescription: "Retention period in days, conflicts with `years`",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+					int64validator.ExactlyOneOf(path.MatchRoot("years")),
+				},
+			},
+			"years": schema.Int64Attribute{
+				MarkdownDescription: "Retention period in years, conflicts with `days`",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+		},
+	}
+}
+
+func (r *BucketObjectLockConfigurationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_bucket_object_lock_configuration")...)
+}
+
+func (r *BucketObjectLockConfigurationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and bucket against bucket_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "bucket", tenantOfBucket)...)
+	resp.Diagnostics.Append(r.client.planBucketPrefix(ctx, req, "bucket")...)
+}
+
+func (r *BucketObjectLockConfigurationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create bucket object lock configuration")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketObjectLockConfigurationResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.S3.PutObjectLockConfiguration(ctx, data.putInput())
+	if err != nil {
+		resp.Diagnostics.AddError("could not set bucket object lock configuration", err.Error())
+		return
+	}
+
+	// use bucket name as resource id
+	data.Id = data.Bucket
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketObjectLockConfigurationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *BucketObjectLockConfigurationResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	s3res, err := r.client.S3.GetObjectLockConfiguration(ctx, &s3.GetObjectLockConfigurationInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && (ae.ErrorCode() == "NoSuchBucket" || ae.ErrorCode() == "ObjectLockConfigurationNotFoundError") {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get bucket object lock configuration", err.Error())
+		return
+	}
+
+	// without a default retention there is nothing managed anymore
+	if s3res.ObjectLockConfiguration == nil || s3res.ObjectLockConfiguration.Rule == nil || s3res.ObjectLockConfiguration.Rule.DefaultRetention == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	retention := s3res.ObjectLockConfiguration.Rule.DefaultRetention
+	data.Mode = types.StringValue(string(retention.Mode))
+	data.Days = types.Int64Null()
+	if retention.Days > 0 {
+		data.Days = types.Int64Value(int64(retention.Days))
+	}
+	data.Years = types.Int64Null()
+	if retention.Years > 0 {
+		data.Years = types.Int64Value(int64(retention.Years))
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_bucket_object_lock_configuration", req.State, resp.State)...)
+}
+
+func (r *BucketObjectLockConfigurationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update bucket object lock configuration")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketObjectLockConfigurationResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.S3.PutObjectLockConfiguration(ctx, data.putInput())
+	if err != nil {
+		resp.Diagnostics.AddError("could not set bucket object lock configuration", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketObjectLockConfigurationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete bucket object lock configuration")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *BucketObjectLockConfigurationResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// object lock can't be disabled anymore, remove the default retention instead
+	_, err := r.client.S3.PutObjectLockConfiguration(ctx, &s3.PutObjectLockConfigurationInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+		ObjectLockConfiguration: &s3types.ObjectLockConfiguration{
+			ObjectLockEnabled: s3types.ObjectLockEnabledEnabled,
+		},
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && ae.ErrorCode() == "NoSuchBucket" {
+			return
+		}
+		resp.Diagnostics.AddError("could not remove bucket default retention", err.Error())
+		return
+	}
+}
+
+func (r *BucketObjectLockConfigurationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("bucket"), req.ID)...)
+}
+
+// putInput converts the model into a PutObjectLockConfiguration request
+func (m *BucketObjectLockConfigurationResourceModel) putInput() *s3.PutObjectLockConfigurationInput {
+	return &s3.PutObjectLockConfigurationInput{
+		Bucket: aws.String(m.Bucket.ValueString()),
+		ObjectLockConfiguration: &s3types.ObjectLockConfiguration{
+			ObjectLockEnabled: s3types.ObjectLockEnabledEnabled,
+			Rule: &s3types.ObjectLockRule{
+				DefaultRetention: &s3types.DefaultRetention{
+					Mode:  s3types.ObjectLockRetentionMode(m.Mode.ValueString()),
+					Days:  int32(m.Days.ValueInt64()),
+					Years: int32(m.Years.ValueInt64()),
+				},
+			},
+		},
+	}
+}
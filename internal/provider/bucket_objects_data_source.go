@@ -0,0 +1,245 @@
+package provider
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = &BucketObjectsDataSource{}
+
+// bucketObjectsMaxKeys bounds the objects kept in the state
+const bucketObjectsMaxKeys = 10000
+
+func NewBucketObjectsDataSource() datasource.DataSource {
+	return &BucketObjectsDataSource{}
+}
+
+type BucketObjectsDataSource struct {
+	client *RgwClient
+}
+
+type BucketObjectsDataSourceModel struct {
+	Id         types.String        `tfsdk:"id"`
+	Bucket     types.String        `tfsdk:"bucket"`
+	Prefix     types.String        `tfsdk:"prefix"`
+	MaxKeys    types.Int64         `tfsdk:"max_keys"`
+	OutputFile types.String        `tfsdk:"output_file"`
+	Objects    []BucketObjectModel `tfsdk:"objects"`
+	Truncated  types.Bool          `tfsdk:"truncated"`
+	TotalSize  types.Int64         `tfsdk:"total_size"`
+}
+
+type BucketObjectModel struct {
+	Key          types.String `tfsdk:"key"`
+	Size         types.Int64  `tfsdk:"size"`
+	LastModified types.String `tfsdk:"last_modified"`
+	StorageClass types.String `tfsdk:"storage_class"`
+	Etag         types.String `tfsdk:"etag"`
+}
+
+func (d *BucketObjectsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_objects"
+}
+
+func (d *BucketObjectsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Inventory-style listing of the objects of a small bucket, e.g. to decide whether to seed a bucket or to skip it. The listing is bounded by `max_keys` and can be exported to a CSV file.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Bucket Name",
+				Required:            true,
+			},
+			"prefix": schema.StringAttribute{
+				MarkdownDescription: "Only list objects whose key starts with this prefix",
+				Optional:            true,
+			},
+			"max_keys": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("Maximum number of objects to list, at most %d. Defaults to `1000`.", bucketObjectsMaxKeys),
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.Between(1, bucketObjectsMaxKeys),
+				},
+			},
+			"output_file": schema.StringAttribute{
+				MarkdownDescription: "Path of a CSV file the listing is written to, with the columns `key`, `size`, `last_modified`, `storage_class` and `etag`",
+				Optional:            true,
+			},
+			"objects": schema.ListNestedAttribute{
+				MarkdownDescription: "The listed objects, sorted by key",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"key": schema.StringAttribute{
+							MarkdownDescription: "Object key",
+							Computed:            true,
+						},
+						"size": schema.Int64Attribute{
+							MarkdownDescription: "Size in bytes",
+							Computed:            true,
+						},
+						"last_modified": schema.StringAttribute{
+							MarkdownDescription: "Modification time in RFC 3339 format",
+							Computed:            true,
+						},
+						"storage_class": schema.StringAttribute{
+							MarkdownDescription: "Storage class",
+							Computed:            true,
+						},
+						"etag": schema.StringAttribute{
+							MarkdownDescription: "ETag",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"truncated": schema.BoolAttribute{
+				MarkdownDescription: "Whether the bucket holds more objects than `max_keys`",
+				Computed:            true,
+			},
+			"total_size": schema.Int64Attribute{
+				MarkdownDescription: "Size of the listed objects in bytes",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *BucketObjectsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "data.rgw_bucket_objects")...)
+}
+
+func (d *BucketObjectsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *BucketObjectsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	maxKeys := int64(1000)
+	if !data.MaxKeys.IsNull() {
+		maxKeys = data.MaxKeys.ValueInt64()
+	}
+
+	s3req := &s3.ListObjectsV2Input{
+		Bucket: aws.String(data.Bucket.ValueString()),
+	}
+	if !data.Prefix.IsNull() {
+		s3req.Prefix = aws.String(data.Prefix.ValueString())
+	}
+
+	data.Objects = []BucketObjectModel{}
+	data.Truncated = types.BoolValue(false)
+	totalSize := int64(0)
+
+	// the listing is truncated if another object follows the last one kept
+	paginator := s3.NewListObjectsV2Paginator(d.client.S3, s3req)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			resp.Diagnostics.AddError("could not list objects", err.Error())
+			return
+		}
+
+		for _, o := range page.Contents {
+			if int64(len(data.Objects)) == maxKeys {
+				data.Truncated = types.BoolValue(true)
+				break
+			}
+
+			lastModified := ""
+			if o.LastModified != nil {
+				lastModified = o.LastModified.UTC().Format(time.RFC3339)
+			}
+			data.Objects = append(data.Objects, BucketObjectModel{
+				Key:          types.StringValue(aws.StringValue(o.Key)),
+				Size:         types.Int64Value(o.Size),
+				LastModified: types.StringValue(lastModified),
+				StorageClass: types.StringValue(string(o.StorageClass)),
+				Etag:         types.StringValue(aws.StringValue(o.ETag)),
+			})
+			totalSize += o.Size
+		}
+		if data.Truncated.ValueBool() {
+			break
+		}
+	}
+
+	data.TotalSize = types.Int64Value(totalSize)
+
+	if !data.OutputFile.IsNull() {
+		if err := writeBucketObjectsCsv(data.OutputFile.ValueString(), data.Objects); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("output_file"), "could not write objects", err.Error())
+			return
+		}
+	}
+
+	data.Id = types.StringValue(fmt.Sprintf("%s/%s", data.Bucket.ValueString(), data.Prefix.ValueString()))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// writeBucketObjectsCsv writes a listing to a CSV file with a header line
+func writeBucketObjectsCsv(name string, objects []BucketObjectModel) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(f)
+	_ = w.Write([]string{"key", "size", "last_modified", "storage_class", "etag"})
+	for _, o := range objects {
+		_ = w.Write([]string{
+			o.Key.ValueString(),
+			strconv.FormatInt(o.Size.ValueInt64(), 10),
+			o.LastModified.ValueString(),
+			o.StorageClass.ValueString(),
+			o.Etag.ValueString(),
+		})
+	}
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
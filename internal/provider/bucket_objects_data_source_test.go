@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestWriteBucketObjectsCsv(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "objects.csv")
+	objects := []BucketObjectModel{{
+		Key:          types.StringValue("seed/a,b.txt"),
+		Size:         types.Int64Value(42),
+		LastModified: types.StringValue("2023-01-02T03:04:05Z"),
+		StorageClass: types.StringValue("STANDARD"),
+		Etag:         types.StringValue(`"d41d8cd98f00b204e9800998ecf8427e"`),
+	}}
+
+	if err := writeBucketObjectsCsv(output, objects); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "key,size,last_modified,storage_class,etag\n" +
+		`"seed/a,b.txt",42,2023-01-02T03:04:05Z,STANDARD,"""d41d8cd98f00b204e9800998ecf8427e"""` + "\n"
+	if string(b) != expected {
+		t.Errorf("unexpected csv:\n%s", b)
+	}
+}
@@ -8,15 +8,18 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/smithy-go"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.ResourceWithConfigure = &BucketPolicyResource{}
+var _ resource.ResourceWithModifyPlan = &BucketPolicyResource{}
 
 func NewBucketPolicyResource() resource.Resource {
 	return &BucketPolicyResource{}
@@ -55,8 +58,11 @@ func (r *BucketPolicyResource) Schema(ctx context.Context, req resource.SchemaRe
 				},
 			},
 			"policy": schema.StringAttribute{
-				MarkdownDescription: "Bucket Policy",
+				MarkdownDescription: "Bucket Policy. Condition keys not evaluated by RGW (e.g. `aws:SourceVpc`) and resources not qualified with the tenant of the bucket (`arn:aws:s3::<tenant>:<bucket>`) produce a warning.",
 				Required:            true,
+				Validators: []validator.String{
+					policyConditionValidator{},
+				},
 			},
 		},
 	}
@@ -80,9 +86,47 @@ func (r *BucketPolicyResource) Configure(ctx context.Context, req resource.Confi
 	}
 
 	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_bucket_policy")...)
+}
+
+func (r *BucketPolicyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and bucket against bucket_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "bucket", tenantOfBucket)...)
+	resp.Diagnostics.Append(r.client.planBucketPrefix(ctx, req, "bucket")...)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	// warn about resources which don't match the tenant of the bucket
+	var data *BucketPolicyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.Bucket.IsUnknown() || data.Policy.IsUnknown() {
+		return
+	}
+	resp.Diagnostics.Append(checkPolicyResources(data.Bucket.ValueString(), data.Policy.ValueString())...)
+
+	// let rgw parse changed policies on the validation bucket if configured
+	var state *BucketPolicyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if state == nil || !state.Policy.Equal(data.Policy) {
+		resp.Diagnostics.Append(r.client.validateBucketPolicy(ctx, path.Root("policy"), data.Policy.ValueString())...)
+	}
 }
 
 func (r *BucketPolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create bucket policy")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Read Terraform plan data into the model
 	var data *BucketPolicyResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -144,9 +188,18 @@ func (r *BucketPolicyResource) Read(ctx context.Context, req resource.ReadReques
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_bucket_policy", req.State, resp.State)...)
 }
 
 func (r *BucketPolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update bucket policy")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Read Terraform plan data into the model
 	var data *BucketPolicyResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -172,6 +225,12 @@ func (r *BucketPolicyResource) Update(ctx context.Context, req resource.UpdateRe
 }
 
 func (r *BucketPolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete bucket policy")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Read Terraform prior state data into the model
 	var data *BucketPolicyResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
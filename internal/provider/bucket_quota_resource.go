@@ -0,0 +1,295 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &BucketQuotaResource{}
+var _ resource.ResourceWithModifyPlan = &BucketQuotaResource{}
+var _ resource.ResourceWithImportState = &BucketQuotaResource{}
+
+func NewBucketQuotaResource() resource.Resource {
+	return &BucketQuotaResource{}
+}
+
+type BucketQuotaResource struct {
+	client *RgwClient
+}
+
+type BucketQuotaResourceModel struct {
+	Id         types.String `tfsdk:"id"`
+	Bucket     types.String `tfsdk:"bucket"`
+	Owner      types.String `tfsdk:"owner"`
+	Enabled    types.Bool   `tfsdk:"enabled"`
+	MaxSize    types.Int64  `tfsdk:"max_size"`
+	MaxObjects types.Int64  `tfsdk:"max_objects"`
+}
+
+func (r *BucketQuotaResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_quota"
+}
+
+func (r *BucketQuotaResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Quota of an individual bucket, overriding the bucket quota of its owner. On destroy the quota of the bucket is disabled.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Bucket Name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"owner": schema.StringAttribute{
+				MarkdownDescription: "The user ID of the bucket owner",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				MarkdownDescription: "Enable or disable the bucket quota",
+				Required:            true,
+			},
+			"max_size": schema.Int64Attribute{
+				MarkdownDescription: "Maximum size in bytes. If not set or -1, it means unlimited.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64DefaultModifier{-1},
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"max_objects": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of objects. If not set or -1, it means unlimited.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64DefaultModifier{-1},
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *BucketQuotaResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_bucket_quota")...)
+}
+
+func (r *BucketQuotaResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and bucket against bucket_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "bucket", tenantOfBucket)...)
+	resp.Diagnostics.Append(r.client.planBucketPrefix(ctx, req, "bucket")...)
+}
+
+func (r *BucketQuotaResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create bucket quota")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketQuotaResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the quota api needs the owner of the bucket
+	bucket, err := r.client.Admin.GetBucketInfo(ctx, admin.Bucket{Bucket: data.Bucket.ValueString()})
+	if err != nil {
+		resp.Diagnostics.AddError("could not get bucket info", err.Error())
+		return
+	}
+	data.Owner = types.StringValue(bucket.Owner)
+
+	err = r.setBucketQuota(ctx, data)
+	if err != nil {
+		resp.Diagnostics.AddError("could not set bucket quota", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(r.verifyBucketQuota(ctx, data)...)
+
+	data.Id = data.Bucket
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketQuotaResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *BucketQuotaResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	bucket, err := r.client.Admin.GetBucketInfo(ctx, admin.Bucket{Bucket: data.Bucket.ValueString()})
+	if err != nil {
+		if errors.Is(err, admin.ErrNoSuchBucket) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get bucket info", err.Error())
+		return
+	}
+
+	data.Owner = types.StringValue(bucket.Owner)
+
+	quota := bucket.BucketQuota
+	data.Enabled = types.BoolValue(quota.Enabled != nil && *quota.Enabled)
+	if quota.MaxSize != nil {
+		data.MaxSize = types.Int64Value(*quota.MaxSize)
+	} else {
+		data.MaxSize = types.Int64Value(-1)
+	}
+	if quota.MaxObjects != nil {
+		data.MaxObjects = types.Int64Value(*quota.MaxObjects)
+	} else {
+		data.MaxObjects = types.Int64Value(-1)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_bucket_quota", req.State, resp.State)...)
+}
+
+func (r *BucketQuotaResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update bucket quota")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketQuotaResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.setBucketQuota(ctx, data)
+	if err != nil {
+		resp.Diagnostics.AddError("could not set bucket quota", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(r.verifyBucketQuota(ctx, data)...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketQuotaResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete bucket quota")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *BucketQuotaResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// a quota can't be removed, reset it to disabled and unlimited instead
+	data.Enabled = types.BoolValue(false)
+	data.MaxSize = types.Int64Value(-1)
+	data.MaxObjects = types.Int64Value(-1)
+
+	err := r.setBucketQuota(ctx, data)
+	if err != nil && !errors.Is(err, admin.ErrNoSuchBucket) && !errors.Is(err, admin.ErrNoSuchUser) {
+		resp.Diagnostics.AddError("could not reset bucket quota", err.Error())
+		return
+	}
+}
+
+func (r *BucketQuotaResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("bucket"), req.ID)...)
+}
+
+// setBucketQuota sets the quota of an individual bucket
+func (r *BucketQuotaResource) setBucketQuota(ctx context.Context, data *BucketQuotaResourceModel) error {
+	enabled := data.Enabled.ValueBool()
+	maxSize := data.MaxSize.ValueInt64()
+	maxObjects := data.MaxObjects.ValueInt64()
+
+	return r.client.Admin.SetIndividualBucketQuota(ctx, admin.QuotaSpec{
+		UID:        data.Owner.ValueString(),
+		Bucket:     data.Bucket.ValueString(),
+		Enabled:    &enabled,
+		MaxSize:    &maxSize,
+		MaxObjects: &maxObjects,
+	})
+}
+
+// verifyBucketQuota verifies that the quota set with setBucketQuota is visible
+func (r *BucketQuotaResource) verifyBucketQuota(ctx context.Context, data *BucketQuotaResourceModel) diag.Diagnostics {
+	return r.client.verifyQuota(ctx, fmt.Sprintf("quota of bucket '%s'", data.Bucket.ValueString()), func(ctx context.Context) (bool, error) {
+		bucket, err := r.client.Admin.GetBucketInfo(ctx, admin.Bucket{Bucket: data.Bucket.ValueString()})
+		if err != nil {
+			return false, err
+		}
+		// unset limits are unlimited, like in Read
+		quota := bucket.BucketQuota
+		enabled := quota.Enabled != nil && *quota.Enabled
+		maxSize, maxObjects := int64(-1), int64(-1)
+		if quota.MaxSize != nil {
+			maxSize = *quota.MaxSize
+		}
+		if quota.MaxObjects != nil {
+			maxObjects = *quota.MaxObjects
+		}
+		return enabled == data.Enabled.ValueBool() && maxSize == data.MaxSize.ValueInt64() && maxObjects == data.MaxObjects.ValueInt64(), nil
+	})
+}
@@ -0,0 +1,303 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &BucketRateLimitResource{}
+var _ resource.ResourceWithModifyPlan = &BucketRateLimitResource{}
+var _ resource.ResourceWithImportState = &BucketRateLimitResource{}
+
+func NewBucketRateLimitResource() resource.Resource {
+	return &BucketRateLimitResource{}
+}
+
+type BucketRateLimitResource struct {
+	client *RgwClient
+}
+
+type BucketRateLimitResourceModel struct {
+	Id            types.String `tfsdk:"id"`
+	Bucket        types.String `tfsdk:"bucket"`
+	Enabled       types.Bool   `tfsdk:"enabled"`
+	MaxReadOps    types.Int64  `tfsdk:"max_read_ops"`
+	MaxWriteOps   types.Int64  `tfsdk:"max_write_ops"`
+	MaxReadBytes  types.Int64  `tfsdk:"max_read_bytes"`
+	MaxWriteBytes types.Int64  `tfsdk:"max_write_bytes"`
+}
+
+// rateLimitSpec is a rate limit as returned by the ratelimit admin api
+type rateLimitSpec struct {
+	Enabled       bool  `json:"enabled"`
+	MaxReadOps    int64 `json:"max_read_ops"`
+	MaxWriteOps   int64 `json:"max_write_ops"`
+	MaxReadBytes  int64 `json:"max_read_bytes"`
+	MaxWriteBytes int64 `json:"max_write_bytes"`
+}
+
+func (r *BucketRateLimitResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_rate_limit"
+}
+
+func (r *BucketRateLimitResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	limit := func(description string) schema.Int64Attribute {
+		return schema.Int64Attribute{
+			MarkdownDescription: description + " per minute and RGW instance. If not set or 0, it means unlimited.",
+			Optional:            true,
+			Computed:            true,
+			PlanModifiers: []planmodifier.Int64{
+				int64DefaultModifier{0},
+				int64planmodifier.UseStateForUnknown(),
+			},
+			Validators: []validator.Int64{
+				int64validator.AtLeast(0),
+			},
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Rate limit of an individual bucket, to throttle noisy buckets. Requires Ceph >= 17.2 (Quincy). On destroy the rate limit of the bucket is disabled.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Bucket Name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				MarkdownDescription: "Enable or disable the bucket rate limit",
+				Required:            true,
+			},
+			"max_read_ops":    limit("Maximum number of read requests"),
+			"max_write_ops":   limit("Maximum number of write requests"),
+			"max_read_bytes":  limit("Maximum number of bytes read"),
+			"max_write_bytes": limit("Maximum number of bytes written"),
+		},
+	}
+}
+
+func (r *BucketRateLimitResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_bucket_rate_limit")...)
+}
+
+func (r *BucketRateLimitResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and bucket against bucket_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "bucket", tenantOfBucket)...)
+	resp.Diagnostics.Append(r.client.planBucketPrefix(ctx, req, "bucket")...)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.client.requireFeature(featureRatelimits)...)
+}
+
+func (r *BucketRateLimitResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create bucket rate limit")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketRateLimitResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.setBucketRateLimit(ctx, data.Bucket.ValueString(), rateLimitFromModel(data))
+	if err != nil {
+		resp.Diagnostics.AddError("could not set bucket rate limit", err.Error())
+		return
+	}
+
+	data.Id = data.Bucket
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketRateLimitResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *BucketRateLimitResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	limit, err := r.client.getBucketRateLimit(ctx, data.Bucket.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get bucket rate limit", err.Error())
+		return
+	}
+
+	data.Enabled = types.BoolValue(limit.Enabled)
+	data.MaxReadOps = types.Int64Value(limit.MaxReadOps)
+	data.MaxWriteOps = types.Int64Value(limit.MaxWriteOps)
+	data.MaxReadBytes = types.Int64Value(limit.MaxReadBytes)
+	data.MaxWriteBytes = types.Int64Value(limit.MaxWriteBytes)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_bucket_rate_limit", req.State, resp.State)...)
+}
+
+func (r *BucketRateLimitResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update bucket rate limit")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketRateLimitResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.setBucketRateLimit(ctx, data.Bucket.ValueString(), rateLimitFromModel(data))
+	if err != nil {
+		resp.Diagnostics.AddError("could not set bucket rate limit", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketRateLimitResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete bucket rate limit")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *BucketRateLimitResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// a rate limit can't be removed, reset it to disabled and unlimited instead
+	err := r.client.setBucketRateLimit(ctx, data.Bucket.ValueString(), rateLimitSpec{})
+	if err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("could not reset bucket rate limit", err.Error())
+		return
+	}
+}
+
+func (r *BucketRateLimitResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("bucket"), req.ID)...)
+}
+
+// rateLimitFromModel converts the planned limits
+func rateLimitFromModel(data *BucketRateLimitResourceModel) rateLimitSpec {
+	return rateLimitSpec{
+		Enabled:       data.Enabled.ValueBool(),
+		MaxReadOps:    data.MaxReadOps.ValueInt64(),
+		MaxWriteOps:   data.MaxWriteOps.ValueInt64(),
+		MaxReadBytes:  data.MaxReadBytes.ValueInt64(),
+		MaxWriteBytes: data.MaxWriteBytes.ValueInt64(),
+	}
+}
+
+// bucketRateLimitArgs returns the arguments selecting the rate limit of a
+// bucket, the api expects the tenant separately
+func bucketRateLimitArgs(bucket string) url.Values {
+	tenant, name := splitTenant(bucket, bucketTenantSeparators)
+	args := url.Values{
+		"ratelimit-scope": []string{"bucket"},
+		"bucket":          []string{name},
+	}
+	if tenant != "" {
+		args.Set("tenant", tenant[:len(tenant)-1])
+	}
+	return args
+}
+
+// getBucketRateLimit gets the rate limit of a bucket
+func (c *RgwClient) getBucketRateLimit(ctx context.Context, bucket string) (*rateLimitSpec, error) {
+	body, err := c.adminCall(ctx, http.MethodGet, "/ratelimit", bucketRateLimitArgs(bucket), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := struct {
+		BucketRateLimit rateLimitSpec `json:"bucket_ratelimit"`
+	}{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("could not decode bucket rate limit: %w", err)
+	}
+
+	return &result.BucketRateLimit, nil
+}
+
+// setBucketRateLimit sets the rate limit of a bucket
+func (c *RgwClient) setBucketRateLimit(ctx context.Context, bucket string, limit rateLimitSpec) error {
+	args := bucketRateLimitArgs(bucket)
+	args.Set("enabled", strconv.FormatBool(limit.Enabled))
+	args.Set("max-read-ops", strconv.FormatInt(limit.MaxReadOps, 10))
+	args.Set("max-write-ops", strconv.FormatInt(limit.MaxWriteOps, 10))
+	args.Set("max-read-bytes", strconv.FormatInt(limit.MaxReadBytes, 10))
+	args.Set("max-write-bytes", strconv.FormatInt(limit.MaxWriteBytes, 10))
+
+	_, err := c.adminCall(ctx, http.MethodPost, "/ratelimit", args, nil)
+	return err
+}
@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestBucketRateLimitTenant(t *testing.T) {
+	var query url.Values
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/admin/ratelimit" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`{"bucket_ratelimit":{"max_read_ops":100,"max_write_ops":0,"max_read_bytes":0,"max_write_bytes":1048576,"enabled":true}}`))
+	})
+
+	limit, err := client.getBucketRateLimit(context.Background(), "team/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if query.Get("ratelimit-scope") != "bucket" || query.Get("tenant") != "team" || query.Get("bucket") != "data" {
+		t.Errorf("unexpected query %v", query)
+	}
+	if !limit.Enabled || limit.MaxReadOps != 100 || limit.MaxWriteBytes != 1048576 {
+		t.Errorf("unexpected rate limit %+v", limit)
+	}
+
+	if err := client.setBucketRateLimit(context.Background(), "data", rateLimitSpec{Enabled: true, MaxWriteOps: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if query.Has("tenant") || query.Get("enabled") != "true" || query.Get("max-write-ops") != "10" || query.Get("max-read-ops") != "0" {
+		t.Errorf("unexpected query %v", query)
+	}
+}
@@ -6,11 +6,13 @@ import (
 	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/smithy-go"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -19,6 +21,7 @@ import (
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.ResourceWithConfigure = &BucketResource{}
+var _ resource.ResourceWithModifyPlan = &BucketResource{}
 var _ resource.ResourceWithImportState = &BucketResource{}
 
 func NewBucketResource() resource.Resource {
@@ -30,8 +33,10 @@ type BucketResource struct {
 }
 
 type BucketResourceModel struct {
-	Id   types.String `tfsdk:"id"`
-	Name types.String `tfsdk:"name"`
+	Id            types.String `tfsdk:"id"`
+	Name          types.String `tfsdk:"name"`
+	AdoptExisting types.Bool   `tfsdk:"adopt_existing"`
+	ObjectLock    types.Bool   `tfsdk:"object_lock_enabled"`
 }
 
 func (r *BucketResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -57,6 +62,19 @@ func (r *BucketResource) Schema(ctx context.Context, req resource.SchemaRequest,
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"adopt_existing": schema.BoolAttribute{
+				MarkdownDescription: "If the bucket already exists and is accessible with the provider credentials, adopt it into the state instead of creating it. Useful for bootstrap pipelines that must be re-runnable.",
+				Optional:            true,
+			},
+			"object_lock_enabled": schema.BoolAttribute{
+				MarkdownDescription: "Create the bucket with object lock enabled, which also enables versioning. Object lock can only be enabled on creation, the default retention is managed by `rgw_bucket_object_lock_configuration`. Read back from the bucket, so imported buckets are not replaced.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
 		},
 	}
 }
@@ -79,9 +97,33 @@ func (r *BucketResource) Configure(ctx context.Context, req resource.ConfigureRe
 	}
 
 	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_bucket")...)
+}
+
+func (r *BucketResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "name", tenantOfBucket)...)
+
+	// check name against bucket_prefix, unless the prefix is prepended anyway
+	if !r.client.PrependPrefix {
+		resp.Diagnostics.Append(r.client.planBucketPrefix(ctx, req, "name")...)
+	}
 }
 
 func (r *BucketResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create bucket")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Read Terraform plan data into the model
 	var data *BucketResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -91,15 +133,71 @@ func (r *BucketResource) Create(ctx context.Context, req resource.CreateRequest,
 
 	// Configure CreateBucketInput
 	s3req := &s3.CreateBucketInput{
-		Bucket: aws.String(data.Name.ValueString()),
+		Bucket:                     aws.String(r.client.prefixedBucket(data.Name.ValueString())),
+		ObjectLockEnabledForBucket: data.ObjectLock.ValueBool(),
 	}
 
-	tflog.Info(ctx, fmt.Sprintf("create bucket %s", *s3req.Bucket))
+	// adopt existing bucket if requested
+	adopted := false
+	if data.AdoptExisting.ValueBool() {
+		_, err := r.client.S3.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: s3req.Bucket})
+		if err == nil {
+			tflog.Info(ctx, fmt.Sprintf("adopt existing bucket %s", *s3req.Bucket))
+			adopted = true
+		} else {
+			// HeadBucket has no body, so a missing bucket is reported as NotFound
+			var notFound *s3types.NotFound
+			if !errors.As(err, &notFound) {
+				resp.Diagnostics.AddError("could not check if bucket exists", err.Error())
+				return
+			}
+		}
+	}
 
-	_, err := r.client.S3.CreateBucket(ctx, s3req)
-	if err != nil {
-		resp.Diagnostics.AddError("could not create bucket", err.Error())
-		return
+	if !adopted {
+		tflog.Info(ctx, fmt.Sprintf("create bucket %s", *s3req.Bucket))
+
+		_, err := r.client.S3.CreateBucket(ctx, s3req)
+		var ae smithy.APIError
+		if errors.As(err, &ae) && ae.ErrorCode() == "BucketAlreadyOwnedByYou" {
+			// nothing proves the bucket was created by this provider, e.g. by an
+			// interrupted apply, so never take it over without being asked to
+			resp.Diagnostics.AddError("bucket already exists",
+				fmt.Sprintf("The bucket '%s' is already owned by the provider credentials. Consider importing it with: terraform import <address> '%s' or setting adopt_existing.", *s3req.Bucket, *s3req.Bucket))
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.AddError("could not create bucket", err.Error())
+			return
+		}
+		if len(r.client.DefaultLabels) > 0 {
+			// stamp default_labels as tags, the bucket exists already so failing is no error
+			_, err := r.client.S3.PutBucketTagging(ctx, &s3.PutBucketTaggingInput{
+				Bucket:  s3req.Bucket,
+				Tagging: &s3types.Tagging{TagSet: r.client.labelTags(nil)},
+			})
+			if err != nil {
+				resp.Diagnostics.AddWarning("could not label bucket",
+					fmt.Sprintf("The bucket '%s' was created, but default_labels could not be set as its tags: %s", *s3req.Bucket, err.Error()))
+			}
+		}
+	}
+
+	// object lock can't be enabled on an adopted bucket, so read it back
+	if adopted {
+		objectLock, err := r.client.bucketObjectLockEnabled(ctx, *s3req.Bucket)
+		if err != nil {
+			resp.Diagnostics.AddError("could not get object lock state of bucket", err.Error())
+			return
+		}
+		if !data.ObjectLock.IsUnknown() && data.ObjectLock.ValueBool() != objectLock {
+			resp.Diagnostics.AddAttributeError(path.Root("object_lock_enabled"), "object lock differs",
+				fmt.Sprintf("The existing bucket '%s' has object lock enabled %t, which can't be changed on adoption.", *s3req.Bucket, objectLock))
+			return
+		}
+		data.ObjectLock = types.BoolValue(objectLock)
+	} else {
+		data.ObjectLock = types.BoolValue(data.ObjectLock.ValueBool())
 	}
 
 	data.Id = types.StringValue(*s3req.Bucket)
@@ -142,13 +240,33 @@ func (r *BucketResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
-	data.Name = types.StringValue(*s3req.Bucket)
+	// keep the configured name if prepend_prefix maps it to the bucket
+	if r.client.prefixedBucket(data.Name.ValueString()) != *s3req.Bucket {
+		data.Name = types.StringValue(r.client.unprefixedBucket(*s3req.Bucket))
+	}
+
+	// update object lock
+	objectLock, err := r.client.bucketObjectLockEnabled(ctx, *s3req.Bucket)
+	if err != nil {
+		resp.Diagnostics.AddError("could not get object lock state of bucket", err.Error())
+		return
+	}
+	data.ObjectLock = types.BoolValue(objectLock)
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_bucket", req.State, resp.State)...)
 }
 
 func (r *BucketResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update bucket")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Read Terraform plan data into the model
 	var data *BucketResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -163,6 +281,12 @@ func (r *BucketResource) Update(ctx context.Context, req resource.UpdateRequest,
 }
 
 func (r *BucketResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete bucket")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var data *BucketResourceModel
 
 	// Read Terraform prior state data into the model
@@ -184,8 +308,8 @@ func (r *BucketResource) Delete(ctx context.Context, req resource.DeleteRequest,
 }
 
 func (r *BucketResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// The import ID should be the bucket name
-	bucketName := req.ID
+	// The import ID should be the bucket name, with or without a prepended prefix
+	bucketName := r.client.prefixedBucket(req.ID)
 
 	// Verify the bucket exists by performing a HeadBucket operation
 	s3req := &s3.HeadBucketInput{
@@ -209,7 +333,31 @@ func (r *BucketResource) ImportState(ctx context.Context, req resource.ImportSta
 		return
 	}
 
-	// Set both id and name to the bucket name
+	// object lock can only be set on creation, so it must be known to not replace the bucket
+	objectLock, err := r.client.bucketObjectLockEnabled(ctx, bucketName)
+	if err != nil {
+		resp.Diagnostics.AddError("could not get object lock state of bucket", err.Error())
+		return
+	}
+
+	// Set id to the bucket name and name to it as configured
 	resp.State.SetAttribute(ctx, path.Root("id"), bucketName)
-	resp.State.SetAttribute(ctx, path.Root("name"), bucketName)
+	resp.State.SetAttribute(ctx, path.Root("name"), r.client.unprefixedBucket(bucketName))
+	resp.State.SetAttribute(ctx, path.Root("object_lock_enabled"), objectLock)
+}
+
+// bucketObjectLockEnabled checks whether object lock was enabled on creation of the bucket
+func (c *RgwClient) bucketObjectLockEnabled(ctx context.Context, bucket string) (bool, error) {
+	s3res, err := c.S3.GetObjectLockConfiguration(ctx, &s3.GetObjectLockConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && ae.ErrorCode() == "ObjectLockConfigurationNotFoundError" {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return s3res.ObjectLockConfiguration != nil && s3res.ObjectLockConfiguration.ObjectLockEnabled == s3types.ObjectLockEnabledEnabled, nil
 }
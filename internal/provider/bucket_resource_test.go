@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestBucketObjectLockEnabled(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/worm":
+			_, _ = w.Write([]byte(`<ObjectLockConfiguration><ObjectLockEnabled>Enabled</ObjectLockEnabled></ObjectLockConfiguration>`))
+		case "/plain":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<Error><Code>ObjectLockConfigurationNotFoundError</Code></Error>`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`<Error><Code>AccessDenied</Code></Error>`))
+		}
+	})
+	client.S3Endpoint = client.Admin.Endpoint
+	client.ForcePathStyle = true
+	client.S3 = client.newS3Client("access", "secret")
+
+	if enabled, err := client.bucketObjectLockEnabled(context.Background(), "worm"); err != nil || !enabled {
+		t.Errorf("expected object lock enabled, got %t, %v", enabled, err)
+	}
+	if enabled, err := client.bucketObjectLockEnabled(context.Background(), "plain"); err != nil || enabled {
+		t.Errorf("expected object lock disabled, got %t, %v", enabled, err)
+	}
+	if _, err := client.bucketObjectLockEnabled(context.Background(), "other"); err == nil {
+		t.Error("expected error for denied bucket")
+	}
+}
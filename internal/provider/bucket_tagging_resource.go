@@ -0,0 +1,270 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/smithy-go"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &BucketTaggingResource{}
+var _ resource.ResourceWithModifyPlan = &BucketTaggingResource{}
+var _ resource.ResourceWithImportState = &BucketTaggingResource{}
+
+func NewBucketTaggingResource() resource.Resource {
+	return &BucketTaggingResource{}
+}
+
+type BucketTaggingResource struct {
+	client *RgwClient
+}
+
+type BucketTaggingResourceModel struct {
+	Id     types.String `tfsdk:"id"`
+	Bucket types.String `tfsdk:"bucket"`
+	Tags   types.Map    `tfsdk:"tags"`
+}
+
+func (r *BucketTaggingResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_tagging"
+}
+
+func (r *BucketTaggingResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Tags of a bucket. The resource manages the complete tag set, tags not configured here are removed except the `default_labels` of the provider.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Bucket Name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"tags": schema.MapAttribute{
+				MarkdownDescription: "Tags of the bucket, e.g. `{ team = \"sre\", cost-center = \"4711\" }`",
+				Required:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+					mapvalidator.KeysAre(stringvalidator.LengthBetween(1, 128)),
+					mapvalidator.ValueStringsAre(stringvalidator.LengthAtMost(256)),
+				},
+			},
+		},
+	}
+}
+
+func (r *BucketTaggingResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_bucket_tagging")...)
+}
+
+func (r *BucketTaggingResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and bucket against bucket_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "bucket", tenantOfBucket)...)
+	resp.Diagnostics.Append(r.client.planBucketPrefix(ctx, req, "bucket")...)
+}
+
+func (r *BucketTaggingResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create bucket tagging")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketTaggingResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.S3.PutBucketTagging(ctx, data.putInput(r.client))
+	if err != nil {
+		resp.Diagnostics.AddError("could not set bucket tags", err.Error())
+		return
+	}
+
+	// use bucket name as resource id
+	data.Id = data.Bucket
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketTaggingResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *BucketTaggingResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	s3res, err := r.client.S3.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+	})
+	var tagSet []s3types.Tag
+	if err != nil {
+		var ae smithy.APIError
+		if !errors.As(err, &ae) {
+			resp.Diagnostics.AddError("could not get bucket tags", err.Error())
+			return
+		}
+		switch ae.ErrorCode() {
+		case "NoSuchBucket":
+			resp.State.RemoveResource(ctx)
+			return
+		case "NoSuchTagSet", "NoSuchTagSetError":
+			// all tags were removed outside of terraform, plan to put them back
+		default:
+			resp.Diagnostics.AddError("could not get bucket tags", err.Error())
+			return
+		}
+	} else {
+		tagSet = s3res.TagSet
+	}
+
+	configured := data.Tags.Elements()
+	tags := make(map[string]attr.Value, len(tagSet))
+	for _, tag := range tagSet {
+		key, value := aws.StringValue(tag.Key), aws.StringValue(tag.Value)
+		// default_labels are no drift, unless they are configured as tags too
+		if _, ok := configured[key]; !ok && r.client.isLabel(key, value) {
+			continue
+		}
+		tags[key] = types.StringValue(value)
+	}
+	data.Tags = types.MapValueMust(types.StringType, tags)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_bucket_tagging", req.State, resp.State)...)
+}
+
+func (r *BucketTaggingResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update bucket tagging")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketTaggingResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the tag set is always replaced as a whole
+	_, err := r.client.S3.PutBucketTagging(ctx, data.putInput(r.client))
+	if err != nil {
+		resp.Diagnostics.AddError("could not set bucket tags", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketTaggingResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete bucket tagging")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *BucketTaggingResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// keep the default_labels stamped on creation of the bucket
+	var err error
+	if len(r.client.DefaultLabels) > 0 {
+		_, err = r.client.S3.PutBucketTagging(ctx, &s3.PutBucketTaggingInput{
+			Bucket:  aws.String(data.Bucket.ValueString()),
+			Tagging: &s3types.Tagging{TagSet: r.client.labelTags(nil)},
+		})
+	} else {
+		_, err = r.client.S3.DeleteBucketTagging(ctx, &s3.DeleteBucketTaggingInput{
+			Bucket: aws.String(data.Bucket.ValueString()),
+		})
+	}
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && ae.ErrorCode() == "NoSuchBucket" {
+			return
+		}
+		resp.Diagnostics.AddError("could not delete bucket tags", err.Error())
+		return
+	}
+}
+
+func (r *BucketTaggingResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("bucket"), req.ID)...)
+}
+
+// putInput converts the model into a PutBucketTagging request, merged with
+// the default_labels of the client
+func (m *BucketTaggingResourceModel) putInput(c *RgwClient) *s3.PutBucketTaggingInput {
+	tags := make(map[string]string, len(m.Tags.Elements()))
+	for k, v := range m.Tags.Elements() {
+		value, _ := v.(types.String)
+		tags[k] = value.ValueString()
+	}
+
+	return &s3.PutBucketTaggingInput{
+		Bucket:  aws.String(m.Bucket.ValueString()),
+		Tagging: &s3types.Tagging{TagSet: c.labelTags(tags)},
+	}
+}
@@ -0,0 +1,262 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/smithy-go"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &BucketVersioningResource{}
+var _ resource.ResourceWithModifyPlan = &BucketVersioningResource{}
+var _ resource.ResourceWithImportState = &BucketVersioningResource{}
+
+func NewBucketVersioningResource() resource.Resource {
+	return &BucketVersioningResource{}
+}
+
+type BucketVersioningResource struct {
+	client *RgwClient
+}
+
+type BucketVersioningResourceModel struct {
+	Id        types.String `tfsdk:"id"`
+	Bucket    types.String `tfsdk:"bucket"`
+	Status    types.String `tfsdk:"status"`
+	MfaDelete types.String `tfsdk:"mfa_delete"`
+	Mfa       types.String `tfsdk:"mfa"`
+}
+
+func (r *BucketVersioningResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_versioning"
+}
+
+func (r *BucketVersioningResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Versioning state of a bucket. Versioning can't be disabled once it was enabled, so on destroy it is suspended.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Bucket Name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"status": schema.StringAttribute{
+				MarkdownDescription: "The versioning state of the bucket: `Enabled` or `Suspended`",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(string(s3types.BucketVersioningStatusEnabled), string(s3types.BucketVersioningStatusSuspended)),
+				},
+			},
+			"mfa_delete": schema.StringAttribute{
+				MarkdownDescription: "Whether deleting object versions and changing the versioning state requires MFA: `Enabled` or `Disabled`. Changing it requires `mfa`. Left untouched if not set.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(string(s3types.MFADeleteEnabled), string(s3types.MFADeleteDisabled)),
+				},
+			},
+			"mfa": schema.StringAttribute{
+				MarkdownDescription: "Serial and current token of the MFA device of the bucket owner, separated by a space. Required to change `mfa_delete` and, once MFA delete is enabled, `status`.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (r *BucketVersioningResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_bucket_versioning")...)
+}
+
+func (r *BucketVersioningResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and bucket against bucket_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "bucket", tenantOfBucket)...)
+	resp.Diagnostics.Append(r.client.planBucketPrefix(ctx, req, "bucket")...)
+}
+
+func (r *BucketVersioningResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create bucket versioning")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketVersioningResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.S3.PutBucketVersioning(ctx, data.putInput(data.Status.ValueString()))
+	if err != nil {
+		resp.Diagnostics.AddError("could not set bucket versioning", err.Error())
+		return
+	}
+
+	// use bucket name as resource id
+	data.Id = data.Bucket
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketVersioningResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *BucketVersioningResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	s3res, err := r.client.S3.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && ae.ErrorCode() == "NoSuchBucket" {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get bucket versioning", err.Error())
+		return
+	}
+
+	// the status is empty if versioning was never enabled
+	data.Status = types.StringValue(string(s3res.Status))
+
+	// only track mfa_delete if it is configured or was enabled outside of terraform
+	mfaDelete := s3res.MFADelete
+	if mfaDelete == "" {
+		mfaDelete = s3types.MFADeleteStatusDisabled
+	}
+	if !data.MfaDelete.IsNull() || mfaDelete == s3types.MFADeleteStatusEnabled {
+		data.MfaDelete = types.StringValue(string(mfaDelete))
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_bucket_versioning", req.State, resp.State)...)
+}
+
+func (r *BucketVersioningResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update bucket versioning")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketVersioningResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.S3.PutBucketVersioning(ctx, data.putInput(data.Status.ValueString()))
+	if err != nil {
+		resp.Diagnostics.AddError("could not set bucket versioning", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketVersioningResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete bucket versioning")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *BucketVersioningResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// versioning can't be disabled anymore, suspend it instead
+	if data.Status.ValueString() != string(s3types.BucketVersioningStatusEnabled) {
+		return
+	}
+	input := data.putInput(string(s3types.BucketVersioningStatusSuspended))
+	input.VersioningConfiguration.MFADelete = ""
+	_, err := r.client.S3.PutBucketVersioning(ctx, input)
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && ae.ErrorCode() == "NoSuchBucket" {
+			return
+		}
+		resp.Diagnostics.AddError("could not suspend bucket versioning", err.Error())
+		return
+	}
+}
+
+func (r *BucketVersioningResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("bucket"), req.ID)...)
+}
+
+// putInput converts the model into a PutBucketVersioning request with the given status
+func (m *BucketVersioningResourceModel) putInput(status string) *s3.PutBucketVersioningInput {
+	input := &s3.PutBucketVersioningInput{
+		Bucket: aws.String(m.Bucket.ValueString()),
+		VersioningConfiguration: &s3types.VersioningConfiguration{
+			Status:    s3types.BucketVersioningStatus(status),
+			MFADelete: s3types.MFADelete(m.MfaDelete.ValueString()),
+		},
+	}
+	if !m.Mfa.IsNull() {
+		input.MFA = aws.String(m.Mfa.ValueString())
+	}
+	return input
+}
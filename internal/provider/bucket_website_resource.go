@@ -0,0 +1,414 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/smithy-go"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &BucketWebsiteResource{}
+var _ resource.ResourceWithModifyPlan = &BucketWebsiteResource{}
+var _ resource.ResourceWithImportState = &BucketWebsiteResource{}
+
+func NewBucketWebsiteResource() resource.Resource {
+	return &BucketWebsiteResource{}
+}
+
+type BucketWebsiteResource struct {
+	client *RgwClient
+}
+
+type BucketWebsiteResourceModel struct {
+	Id                    types.String                    `tfsdk:"id"`
+	Bucket                types.String                    `tfsdk:"bucket"`
+	IndexDocument         types.String                    `tfsdk:"index_document"`
+	ErrorDocument         types.String                    `tfsdk:"error_document"`
+	RedirectAllRequestsTo *BucketWebsiteRedirectAllModel  `tfsdk:"redirect_all_requests_to"`
+	RoutingRules          []BucketWebsiteRoutingRuleModel `tfsdk:"routing_rules"`
+}
+
+type BucketWebsiteRedirectAllModel struct {
+	HostName types.String `tfsdk:"host_name"`
+	Protocol types.String `tfsdk:"protocol"`
+}
+
+type BucketWebsiteRoutingRuleModel struct {
+	ConditionKeyPrefixEquals             types.String `tfsdk:"condition_key_prefix_equals"`
+	ConditionHttpErrorCodeReturnedEquals types.String `tfsdk:"condition_http_error_code_returned_equals"`
+	RedirectHostName                     types.String `tfsdk:"redirect_host_name"`
+	RedirectProtocol                     types.String `tfsdk:"redirect_protocol"`
+	RedirectHttpCode                     types.String `tfsdk:"redirect_http_code"`
+	RedirectReplaceKeyPrefixWith         types.String `tfsdk:"redirect_replace_key_prefix_with"`
+	RedirectReplaceKeyWith               types.String `tfsdk:"redirect_replace_key_with"`
+}
+
+func (r *BucketWebsiteResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_website"
+}
+
+func (r *BucketWebsiteResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	protocolValidator := stringvalidator.OneOf(string(s3types.ProtocolHttp), string(s3types.ProtocolHttps))
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Static website configuration of a bucket, served by the rgw website api (`rgw_enable_static_website`).",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Bucket Name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"index_document": schema.StringAttribute{
+				MarkdownDescription: "Suffix appended to requests for directories, e.g. `index.html`. Conflicts with `redirect_all_requests_to`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.ExactlyOneOf(path.MatchRoot("redirect_all_requests_to")),
+				},
+			},
+			"error_document": schema.StringAttribute{
+				MarkdownDescription: "Key of the object returned for 4XX errors, e.g. `error.html`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.ConflictsWith(path.MatchRoot("redirect_all_requests_to")),
+				},
+			},
+			"redirect_all_requests_to": schema.SingleNestedAttribute{
+				MarkdownDescription: "Redirect all requests to another host. Conflicts with `index_document`.",
+				Optional:            true,
+				Validators: []validator.Object{
+					objectvalidator.ConflictsWith(path.MatchRoot("routing_rules")),
+				},
+				Attributes: map[string]schema.Attribute{
+					"host_name": schema.StringAttribute{
+						MarkdownDescription: "Host requests are redirected to",
+						Required:            true,
+					},
+					"protocol": schema.StringAttribute{
+						MarkdownDescription: "Protocol of the redirects: `http` or `https`. Defaults to the protocol of the request.",
+						Optional:            true,
+						Validators: []validator.String{
+							protocolValidator,
+						},
+					},
+				},
+			},
+			"routing_rules": schema.ListNestedAttribute{
+				MarkdownDescription: "Redirect rules, applied in order",
+				Optional:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"condition_key_prefix_equals": schema.StringAttribute{
+							MarkdownDescription: "Only redirect requests for keys with this prefix",
+							Optional:            true,
+						},
+						"condition_http_error_code_returned_equals": schema.StringAttribute{
+							MarkdownDescription: "Only redirect requests failing with this HTTP error code, e.g. `404`",
+							Optional:            true,
+						},
+						"redirect_host_name": schema.StringAttribute{
+							MarkdownDescription: "Host of the redirect, defaults to the host of the request",
+							Optional:            true,
+						},
+						"redirect_protocol": schema.StringAttribute{
+							MarkdownDescription: "Protocol of the redirect: `http` or `https`. Defaults to the protocol of the request.",
+							Optional:            true,
+							Validators: []validator.String{
+								protocolValidator,
+							},
+						},
+						"redirect_http_code": schema.StringAttribute{
+							MarkdownDescription: "HTTP status code of the redirect, defaults to `301`",
+							Optional:            true,
+						},
+						"redirect_replace_key_prefix_with": schema.StringAttribute{
+							MarkdownDescription: "Replace the prefix matched by `condition_key_prefix_equals` with this prefix. Conflicts with `redirect_replace_key_with`.",
+							Optional:            true,
+						},
+						"redirect_replace_key_with": schema.StringAttribute{
+							MarkdownDescription: "Replace the key with this key. Conflicts with `redirect_replace_key_prefix_with`.",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("redirect_replace_key_prefix_with")),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *BucketWebsiteResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_bucket_website")...)
+}
+
+func (r *BucketWebsiteResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and bucket against bucket_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "bucket", tenantOfBucket)...)
+	resp.Diagnostics.Append(r.client.planBucketPrefix(ctx, req, "bucket")...)
+}
+
+func (r *BucketWebsiteResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create bucket website")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketWebsiteResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.S3.PutBucketWebsite(ctx, data.putInput())
+	if err != nil {
+		resp.Diagnostics.AddError("could not create bucket website configuration", err.Error())
+		return
+	}
+
+	// use bucket name as resource id
+	data.Id = data.Bucket
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketWebsiteResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *BucketWebsiteResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	s3res, err := r.client.S3.GetBucketWebsite(ctx, &s3.GetBucketWebsiteInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && (ae.ErrorCode() == "NoSuchWebsiteConfiguration" || ae.ErrorCode() == "NoSuchBucket") {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get bucket website configuration", err.Error())
+		return
+	}
+
+	data.IndexDocument = types.StringNull()
+	if s3res.IndexDocument != nil {
+		data.IndexDocument = optionalString(s3res.IndexDocument.Suffix)
+	}
+	data.ErrorDocument = types.StringNull()
+	if s3res.ErrorDocument != nil {
+		data.ErrorDocument = optionalString(s3res.ErrorDocument.Key)
+	}
+	data.RedirectAllRequestsTo = nil
+	if s3res.RedirectAllRequestsTo != nil {
+		data.RedirectAllRequestsTo = &BucketWebsiteRedirectAllModel{
+			HostName: types.StringValue(aws.StringValue(s3res.RedirectAllRequestsTo.HostName)),
+			Protocol: optionalString(aws.String(string(s3res.RedirectAllRequestsTo.Protocol))),
+		}
+	}
+	data.RoutingRules = nil
+	for _, rule := range s3res.RoutingRules {
+		data.RoutingRules = append(data.RoutingRules, bucketWebsiteRoutingRuleFromApi(rule))
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_bucket_website", req.State, resp.State)...)
+}
+
+func (r *BucketWebsiteResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update bucket website")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *BucketWebsiteResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the configuration is always replaced as a whole
+	_, err := r.client.S3.PutBucketWebsite(ctx, data.putInput())
+	if err != nil {
+		resp.Diagnostics.AddError("could not modify bucket website configuration", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BucketWebsiteResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete bucket website")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *BucketWebsiteResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.S3.DeleteBucketWebsite(ctx, &s3.DeleteBucketWebsiteInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && ae.ErrorCode() == "NoSuchBucket" {
+			return
+		}
+		resp.Diagnostics.AddError("could not delete bucket website configuration", err.Error())
+		return
+	}
+}
+
+func (r *BucketWebsiteResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("bucket"), req.ID)...)
+}
+
+// putInput converts the model into a PutBucketWebsite request
+func (m *BucketWebsiteResourceModel) putInput() *s3.PutBucketWebsiteInput {
+	config := &s3types.WebsiteConfiguration{}
+	if !m.IndexDocument.IsNull() {
+		config.IndexDocument = &s3types.IndexDocument{Suffix: aws.String(m.IndexDocument.ValueString())}
+	}
+	if !m.ErrorDocument.IsNull() {
+		config.ErrorDocument = &s3types.ErrorDocument{Key: aws.String(m.ErrorDocument.ValueString())}
+	}
+	if m.RedirectAllRequestsTo != nil {
+		config.RedirectAllRequestsTo = &s3types.RedirectAllRequestsTo{
+			HostName: aws.String(m.RedirectAllRequestsTo.HostName.ValueString()),
+			Protocol: s3types.Protocol(m.RedirectAllRequestsTo.Protocol.ValueString()),
+		}
+	}
+	for _, rule := range m.RoutingRules {
+		routingRule := s3types.RoutingRule{
+			Redirect: &s3types.Redirect{
+				HostName:             stringPointer(rule.RedirectHostName),
+				Protocol:             s3types.Protocol(rule.RedirectProtocol.ValueString()),
+				HttpRedirectCode:     stringPointer(rule.RedirectHttpCode),
+				ReplaceKeyPrefixWith: stringPointer(rule.RedirectReplaceKeyPrefixWith),
+				ReplaceKeyWith:       stringPointer(rule.RedirectReplaceKeyWith),
+			},
+		}
+		if !rule.ConditionKeyPrefixEquals.IsNull() || !rule.ConditionHttpErrorCodeReturnedEquals.IsNull() {
+			routingRule.Condition = &s3types.Condition{
+				KeyPrefixEquals:             stringPointer(rule.ConditionKeyPrefixEquals),
+				HttpErrorCodeReturnedEquals: stringPointer(rule.ConditionHttpErrorCodeReturnedEquals),
+			}
+		}
+		config.RoutingRules = append(config.RoutingRules, routingRule)
+	}
+
+	return &s3.PutBucketWebsiteInput{
+		Bucket:               aws.String(m.Bucket.ValueString()),
+		WebsiteConfiguration: config,
+	}
+}
+
+// bucketWebsiteRoutingRuleFromApi converts a routing rule returned by rgw into the model
+func bucketWebsiteRoutingRuleFromApi(rule s3types.RoutingRule) BucketWebsiteRoutingRuleModel {
+	model := BucketWebsiteRoutingRuleModel{
+		ConditionKeyPrefixEquals:             types.StringNull(),
+		ConditionHttpErrorCodeReturnedEquals: types.StringNull(),
+		RedirectHostName:                     types.StringNull(),
+		RedirectProtocol:                     types.StringNull(),
+		RedirectHttpCode:                     types.StringNull(),
+		RedirectReplaceKeyPrefixWith:         types.StringNull(),
+		RedirectReplaceKeyWith:               types.StringNull(),
+	}
+	if rule.Condition != nil {
+		model.ConditionKeyPrefixEquals = optionalString(rule.Condition.KeyPrefixEquals)
+		model.ConditionHttpErrorCodeReturnedEquals = optionalString(rule.Condition.HttpErrorCodeReturnedEquals)
+	}
+	if rule.Redirect != nil {
+		model.RedirectHostName = optionalString(rule.Redirect.HostName)
+		model.RedirectProtocol = optionalString(aws.String(string(rule.Redirect.Protocol)))
+		model.RedirectHttpCode = optionalString(rule.Redirect.HttpRedirectCode)
+		model.RedirectReplaceKeyPrefixWith = optionalString(rule.Redirect.ReplaceKeyPrefixWith)
+		model.RedirectReplaceKeyWith = optionalString(rule.Redirect.ReplaceKeyWith)
+	}
+
+	return model
+}
+
+// optionalString converts an optional api value into a string attribute,
+// missing and empty values become null
+func optionalString(value *string) types.String {
+	if value == nil || *value == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(*value)
+}
+
+// stringPointer converts an optional string attribute into an api value, null
+// becomes nil
+func stringPointer(value types.String) *string {
+	if value.IsNull() {
+		return nil
+	}
+	return aws.String(value.ValueString())
+}
@@ -0,0 +1,247 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// rgwCapTypes are the admin capability types understood by rgw. Types only
+// available from a certain release on reference the corresponding feature.
+var rgwCapTypes = map[string]*rgwFeature{
+	"bilog":         nil,
+	"buckets":       nil,
+	"datalog":       nil,
+	"info":          nil,
+	"mdlog":         nil,
+	"metadata":      nil,
+	"oidc-provider": nil,
+	"roles":         nil,
+	"usage":         nil,
+	"user-policy":   nil,
+	"users":         nil,
+	"zone":          nil,
+	"ratelimit":     &featureRatelimits,
+	"accounts":      &featureAccounts,
+}
+
+// rgwCapPerms are the permissions a capability can be granted with
+var rgwCapPerms = []string{"*", "read", "write", "read, write"}
+
+// validateCapType checks that rgw understands the capability type
+func (c *RgwClient) validateCapType(capType string) error {
+	// cap types added by newer releases can be allowed via extra_cap_types
+	for _, t := range c.ExtraCapTypes {
+		if t == capType {
+			return nil
+		}
+	}
+
+	feature, ok := rgwCapTypes[capType]
+	if !ok {
+		known := make([]string, 0, len(rgwCapTypes)+len(c.ExtraCapTypes))
+		for t := range rgwCapTypes {
+			known = append(known, t)
+		}
+		known = append(known, c.ExtraCapTypes...)
+		sort.Strings(known)
+		return fmt.Errorf("unknown cap type '%s', expected one of: %s. Cap types of newer releases can be added to extra_cap_types of the provider.", capType, strings.Join(known, ", "))
+	}
+
+	if feature != nil && !c.supports(*feature) {
+		return fmt.Errorf("cap type '%s' requires Ceph >= %s (%s), but the provider is configured for Ceph %s", capType, feature.MinVersion, feature.Release, c.Version)
+	}
+
+	return nil
+}
+
+// requiredCaps are the admin caps of the provider credentials needed by each
+// resource and data source, checked if required_caps_check is "strict"
+var requiredCaps = map[string][]admin.UserCapSpec{
+	"rgw_account":                          {{Type: "accounts", Perm: "read, write"}},
+	"rgw_account_user":                     {{Type: "users", Perm: "read, write"}, {Type: "metadata", Perm: "read"}},
+	"rgw_bucket":                           {},
+	"rgw_bucket_lifecycle_configuration":   {},
+	"rgw_bucket_versioning":                {},
+	"rgw_bucket_object_lock_configuration": {},
+	"rgw_bucket_cors":                      {},
+	"rgw_bucket_website":                   {},
+	"rgw_bucket_tagging":                   {},
+	"rgw_bucket_encryption":                {},
+	"rgw_bucket_quota":                     {{Type: "buckets", Perm: "read, write"}},
+	"rgw_bucket_link":                      {{Type: "buckets", Perm: "read, write"}},
+	"rgw_bucket_rate_limit":                {{Type: "ratelimit", Perm: "read, write"}},
+	"rgw_bucket_policy":                    {},
+	"rgw_user":                             {{Type: "users", Perm: "read, write"}},
+	"rgw_user.extra_attributes":            {{Type: "metadata", Perm: "read, write"}},
+	"rgw_user.purge_data_on_delete":        {{Type: "buckets", Perm: "read, write"}},
+	"rgw_user_key":                         {{Type: "users", Perm: "read, write"}},
+	"rgw_subuser":                          {{Type: "users", Perm: "read, write"}},
+	"rgw_user_default_bucket_quota":        {{Type: "users", Perm: "read, write"}},
+	"rgw_user_policy":                      {{Type: "user-policy", Perm: "read, write"}},
+	"rgw_role":                             {{Type: "roles", Perm: "read, write"}},
+	"rgw_role_policy_attachment":           {{Type: "roles", Perm: "read, write"}},
+	"rgw_oidc_provider":                    {{Type: "oidc-provider", Perm: "read, write"}},
+	"rgw_object":                           {},
+	"rgw_object_copy":                      {},
+	"rgw_topic":                            {},
+	"data.rgw_effective_permissions":       {{Type: "users", Perm: "read"}},
+	"data.rgw_account_migration":           {{Type: "users", Perm: "read"}, {Type: "metadata", Perm: "read"}, {Type: "buckets", Perm: "read"}, {Type: "roles", Perm: "read"}},
+	"data.rgw_bucket_objects":              {},
+	"data.rgw_exists":                      {{Type: "metadata", Perm: "read"}, {Type: "buckets", Perm: "read"}, {Type: "roles", Perm: "read"}},
+	"data.rgw_metadata":                    {{Type: "metadata", Perm: "read"}},
+	"data.rgw_oidc_providers":              {{Type: "oidc-provider", Perm: "read"}},
+	"data.rgw_presigned_url":               {},
+	"data.rgw_quota_defaults":              {{Type: "zone", Perm: "read"}},
+	"data.rgw_usage_summary":               {{Type: "usage", Perm: "read"}, {Type: "metadata", Perm: "read"}},
+	"data.rgw_usage_summary.account_id":    {{Type: "accounts", Perm: "read"}},
+	"data.rgw_user":                        {{Type: "users", Perm: "read"}, {Type: "metadata", Perm: "read"}},
+	"data.rgw_user_quota_usage":            {{Type: "users", Perm: "read"}},
+	"data.rgw_users":                       {{Type: "metadata", Perm: "read"}},
+	"data.rgw_user_subusers":               {{Type: "users", Perm: "read"}},
+}
+
+// adminIdentity is the user of the provider credentials
+type adminIdentity struct {
+	UserId string              `json:"user_id"`
+	Admin  bool                `json:"admin"`
+	Caps   []admin.UserCapSpec `json:"caps"`
+}
+
+// getAdminIdentity gets the user of the provider credentials, cached for the lifetime of the provider
+func (c *RgwClient) getAdminIdentity(ctx context.Context) (*adminIdentity, error) {
+	c.identityLock.Lock()
+	defer c.identityLock.Unlock()
+
+	if c.identity != nil {
+		return c.identity, nil
+	}
+
+	body, err := c.adminCall(ctx, http.MethodGet, "/user", url.Values{"access-key": []string{c.Admin.AccessKey}}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &adminIdentity{}
+	if err := json.Unmarshal(body, identity); err != nil {
+		return nil, fmt.Errorf("could not decode user of provider credentials: %w", err)
+	}
+	c.identity = identity
+
+	return identity, nil
+}
+
+// checkRequiredCaps returns an error diagnostic listing the caps missing for
+// the resource or data source if required_caps_check is "strict"
+func (c *RgwClient) checkRequiredCaps(ctx context.Context, typeName string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if c.RequiredCapsCheck != "strict" {
+		return diags
+	}
+
+	identity, err := c.getAdminIdentity(ctx)
+	if err != nil {
+		diags.AddError("could not get caps of the provider credentials", err.Error())
+		return diags
+	}
+	if identity.Admin {
+		return diags
+	}
+
+	var missing []string
+	for _, required := range requiredCaps[typeName] {
+		if !hasCap(identity.Caps, required) {
+			missing = append(missing, fmt.Sprintf("%s=%s", required.Type, required.Perm))
+		}
+	}
+	if len(missing) > 0 {
+		diags.AddError("missing caps",
+			fmt.Sprintf("%s requires the caps '%s' which the user '%s' of the provider credentials lacks. Grant them with: radosgw-admin caps add --uid='%s' --caps='%s'",
+				typeName, strings.Join(missing, "; "), identity.UserId, identity.UserId, strings.Join(missing, "; ")))
+	}
+
+	return diags
+}
+
+// hasCap checks whether the granted caps include the required cap
+func hasCap(granted []admin.UserCapSpec, required admin.UserCapSpec) bool {
+	for _, g := range granted {
+		if g.Type != required.Type {
+			continue
+		}
+		if g.Perm == "*" {
+			return true
+		}
+		for _, perm := range strings.Split(required.Perm, ",") {
+			if !strings.Contains(g.Perm, strings.TrimSpace(perm)) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// adminCapOfPath maps admin api endpoints to the cap type guarding them
+var adminCapOfPath = map[string]string{
+	"account":   "accounts",
+	"bucket":    "buckets",
+	"info":      "info",
+	"metadata":  "metadata",
+	"ratelimit": "ratelimit",
+	"usage":     "usage",
+	"user":      "users",
+}
+
+// capsHintClient turns AccessDenied responses of the admin api into errors
+// naming the admin cap required by the endpoint
+type capsHintClient struct {
+	next admin.HTTPClient
+}
+
+func (c *capsHintClient) Do(req *http.Request) (*http.Response, error) {
+	resp, err := c.next.Do(req)
+	if err != nil || resp.StatusCode != http.StatusForbidden {
+		return resp, err
+	}
+
+	i := strings.Index(req.URL.Path, "/admin/")
+	if i < 0 {
+		return resp, nil
+	}
+	endpoint := strings.SplitN(req.URL.Path[i+len("/admin/"):], "/", 2)[0]
+	capType, ok := adminCapOfPath[endpoint]
+	if !ok {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	apiErr := adminError{StatusCode: resp.StatusCode}
+	if err := json.Unmarshal(body, &apiErr); err != nil || apiErr.Code != admin.ErrAccessDenied.Error() {
+		// not caused by missing caps, leave the response to the caller
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	}
+
+	perm := "write"
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		perm = "read"
+	}
+	apiErr.RequiredCap = fmt.Sprintf("%s=%s", capType, perm)
+
+	return nil, apiErr
+}
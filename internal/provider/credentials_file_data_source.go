@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = &CredentialsFileDataSource{}
+
+func NewCredentialsFileDataSource() datasource.DataSource {
+	return &CredentialsFileDataSource{}
+}
+
+type CredentialsFileDataSource struct {
+	client *RgwClient
+}
+
+type CredentialsFileDataSourceModel struct {
+	Id        types.String `tfsdk:"id"`
+	AccessKey types.String `tfsdk:"access_key"`
+	SecretKey types.String `tfsdk:"secret_key"`
+	Endpoint  types.String `tfsdk:"endpoint"`
+	Region    types.String `tfsdk:"region"`
+	Profile   types.String `tfsdk:"profile"`
+	Format    types.String `tfsdk:"format"`
+	Content   types.String `tfsdk:"content"`
+}
+
+func (d *CredentialsFileDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_credentials_file"
+}
+
+func (d *CredentialsFileDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Renders S3 credentials and the endpoint as AWS credentials file or as JSON with the AWS environment variable names, e.g. for Kubernetes secrets or secrets managers. No api requests are made.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"access_key": schema.StringAttribute{
+				MarkdownDescription: "The access key, e.g. `rgw_user_key.example.access_key`",
+				Required:            true,
+				Sensitive:           true,
+			},
+			"secret_key": schema.StringAttribute{
+				MarkdownDescription: "The secret key, e.g. `rgw_user_key.example.secret_key`",
+				Required:            true,
+				Sensitive:           true,
+			},
+			"endpoint": schema.StringAttribute{
+				MarkdownDescription: "The S3 endpoint URL. Defaults to `s3_endpoint` of the provider.",
+				Optional:            true,
+			},
+			"region": schema.StringAttribute{
+				MarkdownDescription: "The region, i.e. the zonegroup. Omitted if not set.",
+				Optional:            true,
+			},
+			"profile": schema.StringAttribute{
+				MarkdownDescription: "The profile of the `ini` format. Defaults to `default`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"format": schema.StringAttribute{
+				MarkdownDescription: "`ini` for an AWS credentials file, or `json` for an object of `AWS_ACCESS_KEY_ID`, `AWS_SECRET_ACCESS_KEY`, `AWS_ENDPOINT_URL` and `AWS_REGION`. Defaults to `ini`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("ini", "json"),
+				},
+			},
+			"content": schema.StringAttribute{
+				MarkdownDescription: "The rendered credentials",
+				Computed:            true,
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (d *CredentialsFileDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	// only the endpoint is used, so there are no caps to check
+	d.client = client
+}
+
+func (d *CredentialsFileDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *CredentialsFileDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	endpoint := data.Endpoint.ValueString()
+	if data.Endpoint.IsNull() && d.client != nil {
+		endpoint = d.client.S3Endpoint
+	}
+	profile := "default"
+	if !data.Profile.IsNull() {
+		profile = data.Profile.ValueString()
+	}
+
+	credentials := renderedCredentials{
+		AccessKey: data.AccessKey.ValueString(),
+		SecretKey: data.SecretKey.ValueString(),
+		Endpoint:  endpoint,
+		Region:    data.Region.ValueString(),
+	}
+	if data.Format.ValueString() == "json" {
+		content, err := credentials.json()
+		if err != nil {
+			resp.Diagnostics.AddError("could not render credentials", err.Error())
+			return
+		}
+		data.Content = types.StringValue(content)
+	} else {
+		data.Content = types.StringValue(credentials.ini(profile))
+	}
+
+	data.Id = types.StringValue(profile)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// renderedCredentials are the s3 credentials rendered by rgw_credentials_file,
+// empty endpoint and region are omitted
+type renderedCredentials struct {
+	AccessKey string `json:"AWS_ACCESS_KEY_ID"`
+	SecretKey string `json:"AWS_SECRET_ACCESS_KEY"`
+	Endpoint  string `json:"AWS_ENDPOINT_URL,omitempty"`
+	Region    string `json:"AWS_REGION,omitempty"`
+}
+
+// ini renders the credentials as profile of an AWS credentials file
+func (c renderedCredentials) ini(profile string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s]\n", profile)
+	fmt.Fprintf(&b, "aws_access_key_id = %s\n", c.AccessKey)
+	fmt.Fprintf(&b, "aws_secret_access_key = %s\n", c.SecretKey)
+	if c.Endpoint != "" {
+		fmt.Fprintf(&b, "endpoint_url = %s\n", c.Endpoint)
+	}
+	if c.Region != "" {
+		fmt.Fprintf(&b, "region = %s\n", c.Region)
+	}
+	return b.String()
+}
+
+// json renders the credentials as object of the AWS environment variables
+func (c renderedCredentials) json() (string, error) {
+	content, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
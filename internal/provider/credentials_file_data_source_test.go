@@ -0,0 +1,25 @@
+package provider
+
+import "testing"
+
+func TestRenderedCredentials(t *testing.T) {
+	credentials := renderedCredentials{AccessKey: "ACCESS", SecretKey: "secret", Endpoint: "https://s3.example.com"}
+
+	expected := "[app]\naws_access_key_id = ACCESS\naws_secret_access_key = secret\nendpoint_url = https://s3.example.com\n"
+	if ini := credentials.ini("app"); ini != expected {
+		t.Errorf("expected %q, got %q", expected, ini)
+	}
+
+	credentials.Region = "eu"
+	expected = `{"AWS_ACCESS_KEY_ID":"ACCESS","AWS_SECRET_ACCESS_KEY":"secret","AWS_ENDPOINT_URL":"https://s3.example.com","AWS_REGION":"eu"}`
+	if content, err := credentials.json(); err != nil || content != expected {
+		t.Errorf("expected %s, got %s (%v)", expected, content, err)
+	}
+
+	// empty endpoint and region are omitted
+	credentials = renderedCredentials{AccessKey: "ACCESS", SecretKey: "secret"}
+	expected = `{"AWS_ACCESS_KEY_ID":"ACCESS","AWS_SECRET_ACCESS_KEY":"secret"}`
+	if content, _ := credentials.json(); content != expected {
+		t.Errorf("expected %s, got %s", expected, content)
+	}
+}
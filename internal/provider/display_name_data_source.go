@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &DisplayNameDataSource{}
+
+// default templates of display names with and without environment
+const (
+	displayNameTemplate               = "{team}/{service} — {environment}"
+	displayNameTemplateNoEnvironment  = "{team}/{service}"
+	displayNamePlaceholderEnvironment = "{environment}"
+)
+
+var displayNamePlaceholderRegexp = regexp.MustCompile(`\{[a-z_]*\}`)
+
+func NewDisplayNameDataSource() datasource.DataSource {
+	return &DisplayNameDataSource{}
+}
+
+type DisplayNameDataSource struct{}
+
+type DisplayNameDataSourceModel struct {
+	Id          types.String `tfsdk:"id"`
+	Team        types.String `tfsdk:"team"`
+	Service     types.String `tfsdk:"service"`
+	Environment types.String `tfsdk:"environment"`
+	Template    types.String `tfsdk:"template"`
+	DisplayName types.String `tfsdk:"display_name"`
+}
+
+func (d *DisplayNameDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_display_name"
+}
+
+func (d *DisplayNameDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Builds a standardized display name for `rgw_user` from its team, service and environment, to keep naming conventions consistent across many users. No api requests are made.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"team": schema.StringAttribute{
+				MarkdownDescription: "Team owning the user",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"service": schema.StringAttribute{
+				MarkdownDescription: "Service the user belongs to",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"environment": schema.StringAttribute{
+				MarkdownDescription: "Environment of the service, e.g. `production`",
+				Optional:            true,
+			},
+			"template": schema.StringAttribute{
+				MarkdownDescription: "Template of the display name with the placeholders `{team}`, `{service}` and `{environment}`. Defaults to `{team}/{service} — {environment}`, or `{team}/{service}` without `environment`.",
+				Optional:            true,
+			},
+			"display_name": schema.StringAttribute{
+				MarkdownDescription: "The display name, usable as `rgw_user.display_name`",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *DisplayNameDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *DisplayNameDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	template := displayNameTemplate
+	if data.Environment.IsNull() {
+		template = displayNameTemplateNoEnvironment
+	}
+	if !data.Template.IsNull() {
+		template = data.Template.ValueString()
+	}
+
+	if data.Environment.IsNull() && strings.Contains(template, displayNamePlaceholderEnvironment) {
+		resp.Diagnostics.AddAttributeError(path.Root("environment"), "missing environment", "the template uses {environment}, but environment is not set")
+		return
+	}
+
+	displayName, unknown := formatDisplayName(template, map[string]string{
+		"team":        data.Team.ValueString(),
+		"service":     data.Service.ValueString(),
+		"environment": data.Environment.ValueString(),
+	})
+	if unknown != "" {
+		resp.Diagnostics.AddAttributeError(path.Root("template"), "unknown placeholder", "the template contains the unknown placeholder "+unknown+", supported are {team}, {service} and {environment}")
+		return
+	}
+
+	data.DisplayName = types.StringValue(displayName)
+	data.Id = types.StringValue(displayName)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// formatDisplayName replaces the placeholders of the template by the values
+// with their whitespace collapsed, and returns the first unknown placeholder
+func formatDisplayName(template string, values map[string]string) (string, string) {
+	unknown := ""
+	displayName := displayNamePlaceholderRegexp.ReplaceAllStringFunc(template, func(placeholder string) string {
+		value, ok := values[strings.Trim(placeholder, "{}")]
+		if !ok {
+			if unknown == "" {
+				unknown = placeholder
+			}
+			return placeholder
+		}
+		return strings.Join(strings.Fields(value), " ")
+	})
+	return strings.Join(strings.Fields(displayName), " "), unknown
+}
@@ -0,0 +1,22 @@
+package provider
+
+import "testing"
+
+func TestFormatDisplayName(t *testing.T) {
+	values := map[string]string{"team": "payments", "service": " ledger  api ", "environment": "production"}
+
+	for template, expected := range map[string]string{
+		displayNameTemplate:              "payments/ledger api — production",
+		displayNameTemplateNoEnvironment: "payments/ledger api",
+		"{service} ({team})":             "ledger api (payments)",
+	} {
+		displayName, unknown := formatDisplayName(template, values)
+		if unknown != "" || displayName != expected {
+			t.Errorf("expected %q for %q, got %q (unknown %q)", expected, template, displayName, unknown)
+		}
+	}
+
+	if _, unknown := formatDisplayName("{team}-{stage}", values); unknown != "{stage}" {
+		t.Errorf("expected unknown placeholder {stage}, got %q", unknown)
+	}
+}
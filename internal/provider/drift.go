@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// warnDrift reports the attributes changed by a refresh as warning if
+// drift_warnings is set, values of sensitive attributes are redacted
+func (c *RgwClient) warnDrift(ctx context.Context, typeName string, prior tfsdk.State, current tfsdk.State) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !c.DriftWarnings || prior.Raw.IsNull() || current.Raw.IsNull() {
+		return diags
+	}
+
+	diffs, err := prior.Raw.Diff(current.Raw)
+	if err != nil {
+		diags.AddWarning("could not detect drift", err.Error())
+		return diags
+	}
+	if len(diffs) == 0 {
+		return diags
+	}
+
+	var lines []string
+	for _, d := range diffs {
+		value := "(removed)"
+		if d.Value2 != nil {
+			value = driftValue(*d.Value2)
+		}
+		if driftSensitive(ctx, current, d.Path) {
+			value = "(sensitive value)"
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", driftPath(d.Path), value))
+	}
+	sort.Strings(lines)
+
+	var id types.String
+	diags.Append(prior.GetAttribute(ctx, path.Root("id"), &id)...)
+
+	diags.AddWarning(fmt.Sprintf("drift detected on %s '%s'", typeName, id.ValueString()),
+		"The following attributes were changed outside of Terraform, shown with their server-side values:\n\n"+strings.Join(lines, "\n"))
+
+	return diags
+}
+
+// driftSensitive checks whether the attribute or one of its parents is sensitive
+func driftSensitive(ctx context.Context, state tfsdk.State, p *tftypes.AttributePath) bool {
+	steps := p.Steps()
+	for i := 1; i <= len(steps); i++ {
+		attribute, err := state.Schema.AttributeAtTerraformPath(ctx, tftypes.NewAttributePathWithSteps(steps[:i]))
+		if err == nil && attribute.IsSensitive() {
+			return true
+		}
+	}
+	return false
+}
+
+// driftPath renders a path like `caps[0].perm`
+func driftPath(p *tftypes.AttributePath) string {
+	var b strings.Builder
+	for _, step := range p.Steps() {
+		switch s := step.(type) {
+		case tftypes.AttributeName:
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(string(s))
+		case tftypes.ElementKeyString:
+			fmt.Fprintf(&b, "[%q]", string(s))
+		case tftypes.ElementKeyInt:
+			fmt.Fprintf(&b, "[%d]", int64(s))
+		default:
+			b.WriteString("[*]")
+		}
+	}
+	return b.String()
+}
+
+// driftValue renders primitive values, collections are only reported as changed
+func driftValue(v tftypes.Value) string {
+	if !v.IsKnown() {
+		return "(unknown)"
+	}
+	if v.IsNull() {
+		return "null"
+	}
+
+	switch {
+	case v.Type().Is(tftypes.String):
+		var s string
+		if err := v.As(&s); err == nil {
+			return fmt.Sprintf("%q", s)
+		}
+	case v.Type().Is(tftypes.Number):
+		n := new(big.Float)
+		if err := v.As(&n); err == nil {
+			return n.String()
+		}
+	case v.Type().Is(tftypes.Bool):
+		var b bool
+		if err := v.As(&b); err == nil {
+			return fmt.Sprintf("%t", b)
+		}
+	}
+	return "(changed)"
+}
@@ -0,0 +1,423 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/smithy-go"
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = &EffectivePermissionsDataSource{}
+
+func NewEffectivePermissionsDataSource() datasource.DataSource {
+	return &EffectivePermissionsDataSource{}
+}
+
+type EffectivePermissionsDataSource struct {
+	client *RgwClient
+}
+
+type EffectivePermissionsDataSourceModel struct {
+	Id      types.String   `tfsdk:"id"`
+	Bucket  types.String   `tfsdk:"bucket"`
+	UserId  types.String   `tfsdk:"user_id"`
+	Key     types.String   `tfsdk:"key"`
+	Read    types.Bool     `tfsdk:"read"`
+	Write   types.Bool     `tfsdk:"write"`
+	Delete  types.Bool     `tfsdk:"delete"`
+	Admin   types.Bool     `tfsdk:"admin"`
+	Reasons []types.String `tfsdk:"reasons"`
+}
+
+// permissionChecks are the evaluated permissions with the s3 action and the
+// op_mask bit they require and the acl permissions granting them
+var permissionChecks = []struct {
+	Name      string
+	Action    string
+	AclGrants []s3types.Permission
+}{
+	{"read", "s3:GetObject", []s3types.Permission{s3types.PermissionRead, s3types.PermissionFullControl}},
+	{"write", "s3:PutObject", []s3types.Permission{s3types.PermissionWrite, s3types.PermissionFullControl}},
+	{"delete", "s3:DeleteObject", []s3types.Permission{s3types.PermissionWrite, s3types.PermissionFullControl}},
+}
+
+// aclGroups are the acl groups a user is always a member of
+var aclGroups = map[string]string{
+	"http://acs.amazonaws.com/groups/global/AllUsers":           "AllUsers",
+	"http://acs.amazonaws.com/groups/global/AuthenticatedUsers": "AuthenticatedUsers",
+}
+
+func (d *EffectivePermissionsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_effective_permissions"
+}
+
+func (d *EffectivePermissionsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Evaluates whether a user may read, write and delete objects of a bucket, combining the bucket policy, the bucket ACL and the op_mask, suspension and caps of the user. This is a simplified model of the RGW semantics for reviewing access changes: conditional policy statements and object ACLs are not evaluated but reported in `reasons`. The provider credentials must be able to read the bucket policy and ACL, e.g. as a system user.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Bucket Name, optionally qualified with its tenant (`tenant/bucket`)",
+				Required:            true,
+			},
+			"user_id": schema.StringAttribute{
+				MarkdownDescription: "The full user ID (`tenant$username` or `username`).",
+				Required:            true,
+			},
+			"key": schema.StringAttribute{
+				MarkdownDescription: "Object key to evaluate the policy resources against. Defaults to `*`, which only matches policy resources covering all objects of the bucket.",
+				Optional:            true,
+			},
+			"read": schema.BoolAttribute{
+				MarkdownDescription: "Whether the user may read objects (`s3:GetObject`)",
+				Computed:            true,
+			},
+			"write": schema.BoolAttribute{
+				MarkdownDescription: "Whether the user may write objects (`s3:PutObject`)",
+				Computed:            true,
+			},
+			"delete": schema.BoolAttribute{
+				MarkdownDescription: "Whether the user may delete objects (`s3:DeleteObject`)",
+				Computed:            true,
+			},
+			"admin": schema.BoolAttribute{
+				MarkdownDescription: "Whether the caps of the user allow removing the bucket including its objects via the admin api (`buckets=write`)",
+				Computed:            true,
+			},
+			"reasons": schema.ListAttribute{
+				MarkdownDescription: "Explanation of each decision and of everything not evaluated, prefixed with the permission",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *EffectivePermissionsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "data.rgw_effective_permissions")...)
+}
+
+func (d *EffectivePermissionsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *EffectivePermissionsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	key := "*"
+	if !data.Key.IsNull() {
+		key = data.Key.ValueString()
+	}
+
+	user, err := d.client.Admin.GetUser(ctx, admin.User{ID: data.UserId.ValueString()})
+	if err != nil {
+		resp.Diagnostics.AddError("could not get user", err.Error())
+		return
+	}
+
+	acl, err := d.client.S3.GetBucketAcl(ctx, &s3.GetBucketAclInput{Bucket: aws.String(data.Bucket.ValueString())})
+	if err != nil {
+		resp.Diagnostics.AddError("could not get bucket acl", err.Error())
+		return
+	}
+
+	policy := ""
+	policyRes, err := d.client.S3.GetBucketPolicy(ctx, &s3.GetBucketPolicyInput{Bucket: aws.String(data.Bucket.ValueString())})
+	if err != nil {
+		var ae smithy.APIError
+		if !errors.As(err, &ae) || ae.ErrorCode() != "NoSuchBucketPolicy" {
+			resp.Diagnostics.AddError("could not get bucket policy", err.Error())
+			return
+		}
+	} else {
+		policy = aws.StringValue(policyRes.Policy)
+	}
+
+	facts := permissionFacts{
+		UserId:    data.UserId.ValueString(),
+		Suspended: user.Suspended != nil && *user.Suspended > 0,
+		OpMask:    user.OpMask,
+		Caps:      user.Caps,
+		Grants:    acl.Grants,
+		Policy:    policy,
+	}
+	if acl.Owner != nil {
+		facts.Owner = aws.StringValue(acl.Owner.ID)
+	}
+
+	allowed, reasons := evaluatePermissions(data.Bucket.ValueString(), key, facts)
+
+	data.Id = types.StringValue(fmt.Sprintf("%s/%s", data.Bucket.ValueString(), data.UserId.ValueString()))
+	data.Read = types.BoolValue(allowed["read"])
+	data.Write = types.BoolValue(allowed["write"])
+	data.Delete = types.BoolValue(allowed["delete"])
+	data.Admin = types.BoolValue(allowed["admin"])
+	data.Reasons = stringsToModel(reasons)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// permissionFacts is everything the permissions of a user on a bucket are
+// evaluated from
+type permissionFacts struct {
+	UserId    string
+	Suspended bool
+	OpMask    string
+	Caps      []admin.UserCapSpec
+	Owner     string
+	Grants    []s3types.Grant
+	Policy    string
+}
+
+// evaluatePermissions evaluates read, write, delete and admin access of a user
+// to the objects of a bucket in the order rgw does: op_mask, explicit denies
+// and allows of the bucket policy, then the bucket acl
+func evaluatePermissions(bucket string, key string, f permissionFacts) (map[string]bool, []string) {
+	allowed := map[string]bool{}
+	var reasons []string
+
+	var doc policyDocument
+	if f.Policy != "" {
+		if err := json.Unmarshal([]byte(f.Policy), &doc); err != nil {
+			reasons = append(reasons, fmt.Sprintf("policy: could not be parsed and is ignored: %s", err))
+		}
+	}
+
+	userTenant, _ := tenantOfUser(f.UserId)
+	bucketTenant, bucketName := splitTenant(bucket, bucketTenantSeparators)
+	bucketTenant = strings.TrimRight(bucketTenant, bucketTenantSeparators)
+	resource := fmt.Sprintf("arn:aws:s3::%s:%s/%s", bucketTenant, bucketName, key)
+
+	for _, check := range permissionChecks {
+		decide := func(allow bool, format string, args ...interface{}) {
+			allowed[check.Name] = allow
+			reasons = append(reasons, check.Name+": "+fmt.Sprintf(format, args...))
+		}
+
+		if f.Suspended {
+			decide(false, "user is suspended")
+			continue
+		}
+		if !opMaskAllows(f.OpMask, check.Name) {
+			decide(false, "op_mask '%s' of the user does not include %s", f.OpMask, check.Name)
+			continue
+		}
+
+		// explicit denies take precedence over allows
+		var allowedBy, deniedBy string
+		for i, stmt := range doc.Statement {
+			name := stmt.Sid
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			if !statementMatches(stmt, f.UserId, userTenant, check.Action, resource) {
+				continue
+			}
+			if len(stmt.Condition) > 0 {
+				reasons = append(reasons, fmt.Sprintf("%s: conditional policy statement %s (%s) not evaluated", check.Name, name, stmt.Effect))
+				continue
+			}
+			switch {
+			case strings.EqualFold(stmt.Effect, "Deny") && deniedBy == "":
+				deniedBy = name
+			case strings.EqualFold(stmt.Effect, "Allow") && allowedBy == "":
+				allowedBy = name
+			}
+		}
+		if deniedBy != "" {
+			decide(false, "policy statement %s denies %s", deniedBy, check.Action)
+			continue
+		}
+		if allowedBy != "" {
+			decide(true, "policy statement %s allows %s", allowedBy, check.Action)
+			continue
+		}
+
+		if f.Owner == f.UserId {
+			decide(true, "user owns the bucket")
+			continue
+		}
+		if grantee, permission, ok := aclGrants(f.Grants, f.UserId, check.AclGrants); ok {
+			decide(true, "bucket acl grants %s to %s", permission, grantee)
+			continue
+		}
+
+		decide(false, "neither the bucket policy nor the bucket acl allow %s", check.Action)
+	}
+
+	for _, c := range f.Caps {
+		if !f.Suspended && c.Type == "buckets" && (c.Perm == "*" || strings.Contains(c.Perm, "write")) {
+			allowed["admin"] = true
+			reasons = append(reasons, fmt.Sprintf("admin: caps buckets=%s allow removing the bucket via the admin api", c.Perm))
+		}
+	}
+
+	return allowed, reasons
+}
+
+// opMaskAllows checks whether an op_mask like `read, write, delete` includes the operation
+func opMaskAllows(opMask string, op string) bool {
+	for _, o := range strings.Split(opMask, ",") {
+		o = strings.TrimSpace(o)
+		if o == "*" || o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// statementMatches checks whether a policy statement applies to the user,
+// action and resource
+func statementMatches(stmt policyStatement, userId string, userTenant string, action string, resource string) bool {
+	switch {
+	case stmt.Principal != nil:
+		if !principalMatches(stmt.Principal, userId, userTenant) {
+			return false
+		}
+	case stmt.NotPrincipal != nil:
+		if principalMatches(stmt.NotPrincipal, userId, userTenant) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	switch {
+	case stmt.Action != nil:
+		if !anyWildcardMatch(conditionValues(stmt.Action), action, true) {
+			return false
+		}
+	case stmt.NotAction != nil:
+		if anyWildcardMatch(conditionValues(stmt.NotAction), action, true) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	return anyWildcardMatch(conditionValues(stmt.Resource), resource, false)
+}
+
+// principalMatches checks whether a principal (`*` or `{"AWS": [...]}`) names
+// the user, its tenant or everyone
+func principalMatches(raw json.RawMessage, userId string, userTenant string) bool {
+	arns := conditionValues(raw)
+	if arns == nil {
+		var principal map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &principal); err != nil {
+			return false
+		}
+		arns = conditionValues(principal["AWS"])
+	}
+
+	_, username := splitTenant(userId, userTenantSeparators)
+	userArn := fmt.Sprintf("arn:aws:iam::%s:user/%s", userTenant, username)
+	tenantArn := fmt.Sprintf("arn:aws:iam::%s:root", userTenant)
+	for _, arn := range arns {
+		if arn == "*" || arn == tenantArn || wildcardMatch(arn, userArn) {
+			return true
+		}
+	}
+	return false
+}
+
+// aclGrants returns the first grant giving one of the permissions to the
+// user or a group every user belongs to
+func aclGrants(grants []s3types.Grant, userId string, permissions []s3types.Permission) (string, s3types.Permission, bool) {
+	for _, g := range grants {
+		if g.Grantee == nil {
+			continue
+		}
+		grantee := ""
+		switch {
+		case g.Grantee.Type == s3types.TypeCanonicalUser && aws.StringValue(g.Grantee.ID) == userId:
+			grantee = "the user"
+		case g.Grantee.Type == s3types.TypeGroup && aclGroups[aws.StringValue(g.Grantee.URI)] != "":
+			grantee = aclGroups[aws.StringValue(g.Grantee.URI)]
+		default:
+			continue
+		}
+		for _, p := range permissions {
+			if g.Permission == p {
+				return grantee, p, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// anyWildcardMatch checks whether any of the patterns matches the value
+func anyWildcardMatch(patterns []string, value string, ignoreCase bool) bool {
+	for _, p := range patterns {
+		if ignoreCase {
+			if wildcardMatch(strings.ToLower(p), strings.ToLower(value)) {
+				return true
+			}
+		} else if wildcardMatch(p, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardMatch matches a value against a policy pattern, where `*` matches
+// any sequence of characters including `/` and `?` matches a single character
+func wildcardMatch(pattern string, value string) bool {
+	p, v := 0, 0
+	star, mark := -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == value[v]):
+			p++
+			v++
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, v
+			p++
+		case star >= 0:
+			p = star + 1
+			mark++
+			v = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"testing"
+
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+func TestEvaluatePermissions(t *testing.T) {
+	policy := `{"Version":"2012-10-17","Statement":[
+		{"Sid":"ReadOnly","Effect":"Allow","Principal":{"AWS":["arn:aws:iam::team:user/reader"]},"Action":["s3:Get*","s3:List*"],"Resource":["arn:aws:s3::team:data/*"]},
+		{"Sid":"NoDelete","Effect":"Deny","Principal":"*","Action":"s3:DeleteObject","Resource":"arn:aws:s3::team:data/*"},
+		{"Sid":"FromOffice","Effect":"Allow","Principal":"*","Action":"s3:PutObject","Resource":"arn:aws:s3::team:data/*","Condition":{"IpAddress":{"aws:SourceIp":"10.0.0.0/8"}}}
+	]}`
+	grants := []s3types.Grant{
+		{Grantee: &s3types.Grantee{Type: s3types.TypeCanonicalUser, ID: aws.String("team$owner")}, Permission: s3types.PermissionFullControl},
+		{Grantee: &s3types.Grantee{Type: s3types.TypeCanonicalUser, ID: aws.String("team$writer")}, Permission: s3types.PermissionWrite},
+	}
+
+	tests := []struct {
+		name    string
+		facts   permissionFacts
+		allowed map[string]bool
+	}{
+		{
+			name:    "policy allows reading",
+			facts:   permissionFacts{UserId: "team$reader", OpMask: "read, write, delete"},
+			allowed: map[string]bool{"read": true},
+		},
+		{
+			name:    "owner is denied deleting by policy",
+			facts:   permissionFacts{UserId: "team$owner", OpMask: "read, write, delete"},
+			allowed: map[string]bool{"read": true, "write": true},
+		},
+		{
+			name:    "acl grants writing",
+			facts:   permissionFacts{UserId: "team$writer", OpMask: "read, write, delete"},
+			allowed: map[string]bool{"write": true},
+		},
+		{
+			name:    "op_mask restricts writing",
+			facts:   permissionFacts{UserId: "team$owner", OpMask: "read"},
+			allowed: map[string]bool{"read": true},
+		},
+		{
+			name:    "suspended user",
+			facts:   permissionFacts{UserId: "team$owner", OpMask: "*", Suspended: true, Caps: []admin.UserCapSpec{{Type: "buckets", Perm: "*"}}},
+			allowed: map[string]bool{},
+		},
+		{
+			name:    "caps allow admin",
+			facts:   permissionFacts{UserId: "team$ops", OpMask: "*", Caps: []admin.UserCapSpec{{Type: "buckets", Perm: "read, write"}}},
+			allowed: map[string]bool{"admin": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.facts.Owner = "team$owner"
+			tt.facts.Grants = grants
+			tt.facts.Policy = policy
+
+			allowed, reasons := evaluatePermissions("team/data", "*", tt.facts)
+			for _, p := range []string{"read", "write", "delete", "admin"} {
+				if allowed[p] != tt.allowed[p] {
+					t.Errorf("expected %s=%t, got %t: %v", p, tt.allowed[p], allowed[p], reasons)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluatePermissionsResourceKey(t *testing.T) {
+	facts := permissionFacts{
+		UserId: "app",
+		OpMask: "read, write, delete",
+		Policy: `{"Statement":{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam:::user/app"},"Action":"s3:GetObject","Resource":"arn:aws:s3:::public/assets/*"}}`,
+	}
+
+	if allowed, reasons := evaluatePermissions("public", "*", facts); allowed["read"] {
+		t.Errorf("expected a prefix statement not to cover all objects: %v", reasons)
+	}
+	if allowed, reasons := evaluatePermissions("public", "assets/logo.png", facts); !allowed["read"] {
+		t.Errorf("expected the object to be readable: %v", reasons)
+	}
+}
+
+func TestWildcardMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		match   bool
+	}{
+		{"s3:*", "s3:GetObject", true},
+		{"s3:Get*", "s3:PutObject", false},
+		{"arn:aws:s3:::bucket/*", "arn:aws:s3:::bucket/a/b", true},
+		{"arn:aws:s3:::bucket/?", "arn:aws:s3:::bucket/ab", false},
+		{"*", "", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+	}
+
+	for _, tt := range tests {
+		if got := wildcardMatch(tt.pattern, tt.value); got != tt.match {
+			t.Errorf("wildcardMatch(%q, %q) = %t, expected %t", tt.pattern, tt.value, got, tt.match)
+		}
+	}
+}
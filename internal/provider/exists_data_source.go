@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = &ExistsDataSource{}
+
+func NewExistsDataSource() datasource.DataSource {
+	return &ExistsDataSource{}
+}
+
+type ExistsDataSource struct {
+	client *RgwClient
+}
+
+type ExistsDataSourceModel struct {
+	Id     types.String `tfsdk:"id"`
+	Type   types.String `tfsdk:"type"`
+	Exists types.Bool   `tfsdk:"exists"`
+}
+
+func (d *ExistsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_exists"
+}
+
+func (d *ExistsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Checks whether an object exists, e.g. to verify a destroy or to guard composite modules",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of the object: the user ID (`tenant$username`), the bucket name (`tenant/bucket`), the role name or the topic ARN",
+				Required:            true,
+			},
+			"type": schema.StringAttribute{
+				MarkdownDescription: "Type of the object, one of `user`, `bucket`, `role` or `topic`",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("user", "bucket", "role", "topic"),
+				},
+			},
+			"exists": schema.BoolAttribute{
+				MarkdownDescription: "Whether the object exists",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *ExistsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "data.rgw_exists")...)
+}
+
+func (d *ExistsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *ExistsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	name := data.Id.ValueString()
+	var exists bool
+	var err error
+	switch data.Type.ValueString() {
+	case "user":
+		exists, err = d.client.userExists(ctx, name)
+	case "bucket":
+		_, err = d.client.Admin.GetBucketInfo(ctx, admin.Bucket{Bucket: name})
+		exists, err = existsFromError(err, errors.Is(err, admin.ErrNoSuchBucket))
+	case "role":
+		_, err = d.client.iamCall(ctx, "GetRole", url.Values{"RoleName": []string{name}})
+		exists, err = existsFromError(err, isNotFound(err))
+	case "topic":
+		_, err = d.client.snsCall(ctx, "GetTopicAttributes", url.Values{"TopicArn": []string{name}})
+		exists, err = existsFromError(err, isNotFound(err))
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("could not check if %s exists", data.Type.ValueString()), err.Error())
+		return
+	}
+
+	data.Exists = types.BoolValue(exists)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// existsFromError converts the error of a get call into an existence check
+func existsFromError(err error, notFound bool) (bool, error) {
+	if err == nil {
+		return true, nil
+	}
+	if notFound {
+		return false, nil
+	}
+	return false, err
+}
+
+// isNotFound checks whether an api call failed because the object does not exist
+func isNotFound(err error) bool {
+	var apiErr adminError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
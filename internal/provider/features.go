@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// cephVersion is a parsed ceph release version
+type cephVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+func (v cephVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// less reports whether v is an older release than o
+func (v cephVersion) less(o cephVersion) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+// cephReleases maps release names to the version of their first stable release
+var cephReleases = map[string]cephVersion{
+	"octopus":  {15, 2, 0},
+	"pacific":  {16, 2, 0},
+	"quincy":   {17, 2, 0},
+	"reef":     {18, 2, 0},
+	"squid":    {19, 2, 0},
+	"tentacle": {20, 2, 0},
+}
+
+// parseCephVersion parses versions like "18.2.1", "18.2" or release names like "reef"
+func parseCephVersion(s string) (cephVersion, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if v, ok := cephReleases[s]; ok {
+		return v, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return cephVersion{}, fmt.Errorf("invalid ceph version '%s', expected e.g. '18.2.1' or 'reef'", s)
+	}
+
+	var numbers [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return cephVersion{}, fmt.Errorf("invalid ceph version '%s', expected e.g. '18.2.1' or 'reef'", s)
+		}
+		numbers[i] = n
+	}
+
+	return cephVersion{numbers[0], numbers[1], numbers[2]}, nil
+}
+
+// rgwFeature is a feature only available from a certain ceph release on
+type rgwFeature struct {
+	Name       string
+	MinVersion cephVersion
+	Release    string
+}
+
+var (
+	featureRatelimits       = rgwFeature{"rate limits", cephVersion{17, 2, 0}, "Quincy"}
+	featureBucketEncryption = rgwFeature{"bucket encryption", cephVersion{17, 2, 0}, "Quincy"}
+	featureAccounts         = rgwFeature{"accounts", cephVersion{19, 2, 0}, "Squid"}
+	featureManagedPolicies  = rgwFeature{"managed policies", cephVersion{19, 2, 0}, "Squid"}
+)
+
+// supports reports whether the configured ceph version provides the feature.
+// Without a configured version all features are assumed to be available.
+func (c *RgwClient) supports(f rgwFeature) bool {
+	return c.Version == nil || !c.Version.less(f.MinVersion)
+}
+
+// requireFeature returns an error diagnostic if the configured ceph version
+// does not provide the feature
+func (c *RgwClient) requireFeature(f rgwFeature) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !c.supports(f) {
+		diags.AddError(
+			fmt.Sprintf("%s not supported", f.Name),
+			fmt.Sprintf("%s requires Ceph >= %s (%s), but the provider is configured for Ceph %s.", f.Name, f.MinVersion, f.Release, c.Version),
+		)
+	}
+	return diags
+}
@@ -0,0 +1,331 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// tenantOfUser returns the tenant of a user ID (`tenant$username`)
+func tenantOfUser(userId string) (string, bool) {
+	if splitted := strings.SplitN(userId, "$", 2); len(splitted) == 2 {
+		return splitted[0], true
+	}
+	return "", true
+}
+
+// tenantOfBucket returns the tenant of a bucket name (`tenant:bucket` or
+// `tenant/bucket`). Buckets without tenant belong to the tenant of the
+// provider credentials, in which case false is returned.
+func tenantOfBucket(bucket string) (string, bool) {
+	if i := strings.IndexAny(bucket, ":/"); i >= 0 {
+		return bucket[:i], true
+	}
+	return "", false
+}
+
+// tenantOfCredentials returns no explicit tenant, for objects like roles and
+// topics which always belong to the tenant of the provider credentials
+func tenantOfCredentials(name string) (string, bool) {
+	return "", false
+}
+
+// checkTenant checks that the tenant is in allowed_tenants
+func (c *RgwClient) checkTenant(ctx context.Context, tenant string, explicit bool) error {
+	if c.AllowedTenants == nil {
+		return nil
+	}
+
+	if !explicit {
+		identity, err := c.getAdminIdentity(ctx)
+		if err != nil {
+			return fmt.Errorf("could not get tenant of the provider credentials: %w", err)
+		}
+		tenant, _ = tenantOfUser(identity.UserId)
+	}
+
+	for _, t := range c.AllowedTenants {
+		if t == tenant {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("tenant '%s' is not in allowed_tenants (%s) of the provider", tenant, strings.Join(c.AllowedTenants, ", "))
+}
+
+// planTenant checks the tenant of an attribute of the planned resource, or of
+// the prior state on destroy, against allowed_tenants
+func (c *RgwClient) planTenant(ctx context.Context, req resource.ModifyPlanRequest, attribute string, tenantOf func(string) (string, bool)) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if c.AllowedTenants == nil {
+		return diags
+	}
+
+	var value types.String
+	if req.Plan.Raw.IsNull() {
+		diags.Append(req.State.GetAttribute(ctx, path.Root(attribute), &value)...)
+	} else {
+		diags.Append(req.Plan.GetAttribute(ctx, path.Root(attribute), &value)...)
+	}
+	if diags.HasError() || value.IsUnknown() {
+		return diags
+	}
+
+	tenant, explicit := tenantOf(value.ValueString())
+	if err := c.checkTenant(ctx, tenant, explicit); err != nil {
+		diags.AddAttributeError(path.Root(attribute), "tenant not allowed", err.Error())
+	}
+
+	return diags
+}
+
+// isProtectedUid checks whether the user is in protected_uids
+func (c *RgwClient) isProtectedUid(userId string) bool {
+	for _, uid := range c.ProtectedUids {
+		if uid == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// planProtectedUser refuses to destroy or replace a user in protected_uids or
+// to generate new keys for it
+func (c *RgwClient) planProtectedUser(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(c.ProtectedUids) == 0 || req.State.Raw.IsNull() {
+		return diags
+	}
+
+	var userId types.String
+	diags.Append(req.State.GetAttribute(ctx, path.Root("id"), &userId)...)
+	if diags.HasError() || !c.isProtectedUid(userId.ValueString()) {
+		return diags
+	}
+
+	if req.Plan.Raw.IsNull() || len(resp.RequiresReplace) > 0 {
+		diags.AddError("user is protected",
+			fmt.Sprintf("The user '%s' is in protected_uids of the provider and must not be destroyed or replaced. Remove it from the state with `terraform state rm` instead.", userId.ValueString()))
+		return diags
+	}
+
+	var accessKey types.String
+	diags.Append(resp.Plan.GetAttribute(ctx, path.Root("access_key"), &accessKey)...)
+	if accessKey.IsUnknown() {
+		diags.AddError("user is protected",
+			fmt.Sprintf("The user '%s' is in protected_uids of the provider, its keys must not be modified.", userId.ValueString()))
+	}
+
+	return diags
+}
+
+// planProtectedKey refuses to create, destroy or replace a key of a user in protected_uids
+func (c *RgwClient) planProtectedKey(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(c.ProtectedUids) == 0 {
+		return diags
+	}
+
+	// keys are never updated in place, so only no-op plans are fine
+	if !req.State.Raw.IsNull() && !req.Plan.Raw.IsNull() && len(resp.RequiresReplace) == 0 {
+		return diags
+	}
+
+	var userId types.String
+	if req.Plan.Raw.IsNull() {
+		diags.Append(req.State.GetAttribute(ctx, path.Root("user_id"), &userId)...)
+	} else {
+		diags.Append(req.Plan.GetAttribute(ctx, path.Root("user_id"), &userId)...)
+	}
+	if diags.HasError() || !c.isProtectedUid(userId.ValueString()) {
+		return diags
+	}
+
+	diags.AddError("user is protected",
+		fmt.Sprintf("The user '%s' is in protected_uids of the provider, its keys must not be modified.", userId.ValueString()))
+
+	return diags
+}
+
+// s3KeysOfUser returns the s3 access keys of the user itself, without the keys
+// of its subusers and without the excluded key
+func s3KeysOfUser(user admin.User, exclude string) []string {
+	keys := []string{}
+	for _, k := range user.Keys {
+		if (k.User == user.ID || k.User == "") && k.AccessKey != exclude {
+			keys = append(keys, k.AccessKey)
+		}
+	}
+	return keys
+}
+
+// planMaxUserKeys refuses to create a key for a user which has max_user_keys
+// keys already. A replaced key doesn't count, as it is deleted before.
+func (c *RgwClient) planMaxUserKeys(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if c.MaxUserKeys == 0 || req.Plan.Raw.IsNull() {
+		return diags
+	}
+
+	// keys are never updated in place, so only creates and replacements add a key
+	if !req.State.Raw.IsNull() && len(resp.RequiresReplace) == 0 {
+		return diags
+	}
+
+	var userId, replacedKey types.String
+	diags.Append(req.Plan.GetAttribute(ctx, path.Root("user_id"), &userId)...)
+	if !req.State.Raw.IsNull() {
+		diags.Append(req.State.GetAttribute(ctx, path.Root("access_key"), &replacedKey)...)
+	}
+	// users created in the same apply have no keys yet
+	if diags.HasError() || userId.IsUnknown() {
+		return diags
+	}
+
+	user, err := c.Admin.GetUser(ctx, admin.User{ID: userId.ValueString()})
+	if err != nil {
+		if !errors.Is(err, admin.ErrNoSuchUser) {
+			diags.AddWarning("could not check number of keys", fmt.Sprintf("could not get user '%s': %s", userId.ValueString(), err.Error()))
+		}
+		return diags
+	}
+
+	keys := s3KeysOfUser(user, replacedKey.ValueString())
+	if int64(len(keys)) >= c.MaxUserKeys {
+		diags.AddAttributeError(path.Root("user_id"), "too many keys",
+			fmt.Sprintf("The user '%s' has %d keys already (%s), max_user_keys of the provider allows %d. Remove an unused key first or rotate one with `terraform apply -replace`.", userId.ValueString(), len(keys), strings.Join(keys, ", "), c.MaxUserKeys))
+	}
+
+	return diags
+}
+
+// planUserEmail checks the planned email of a user against user_email_policy
+func (c *RgwClient) planUserEmail(ctx context.Context, req resource.ModifyPlanRequest) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if c.UserEmailPolicy == nil || req.Plan.Raw.IsNull() {
+		return diags
+	}
+
+	var email types.String
+	diags.Append(req.Plan.GetAttribute(ctx, path.Root("email"), &email)...)
+	if diags.HasError() || email.IsUnknown() || email.ValueString() == "" {
+		return diags
+	}
+
+	if !c.UserEmailPolicy.MatchString(email.ValueString()) {
+		diags.AddAttributeError(path.Root("email"), "email violates policy",
+			fmt.Sprintf("The email '%s' does not match user_email_policy '%s' of the provider.", email.ValueString(), c.UserEmailPolicy.String()))
+	}
+
+	return diags
+}
+
+// separators between tenant and name of bucket names and user IDs
+const (
+	bucketTenantSeparators = ":/"
+	userTenantSeparators   = "$"
+)
+
+// splitTenant splits a bucket name or user ID into the tenant including its
+// separator and the name
+func splitTenant(name string, separators string) (string, string) {
+	if i := strings.IndexAny(name, separators); i >= 0 {
+		return name[:i+1], name[i+1:]
+	}
+	return "", name
+}
+
+// withPrefix prepends the prefix to the name after the tenant, unless the name
+// starts with it already
+func withPrefix(name string, prefix string, separators string) string {
+	tenant, local := splitTenant(name, separators)
+	if strings.HasPrefix(local, prefix) {
+		return name
+	}
+	return tenant + prefix + local
+}
+
+// prefixedBucket returns the bucket name on the cluster, with bucket_prefix
+// prepended if prepend_prefix is set
+func (c *RgwClient) prefixedBucket(bucket string) string {
+	if !c.PrependPrefix {
+		return bucket
+	}
+	return withPrefix(bucket, c.BucketPrefix, bucketTenantSeparators)
+}
+
+// prefixedUserId returns the user ID on the cluster, with user_prefix
+// prepended if prepend_prefix is set
+func (c *RgwClient) prefixedUserId(userId string) string {
+	if !c.PrependPrefix {
+		return userId
+	}
+	return withPrefix(userId, c.UserPrefix, userTenantSeparators)
+}
+
+// unprefixedBucket returns the bucket name as configured, without the
+// bucket_prefix prepended by prepend_prefix
+func (c *RgwClient) unprefixedBucket(bucket string) string {
+	if !c.PrependPrefix {
+		return bucket
+	}
+	tenant, local := splitTenant(bucket, bucketTenantSeparators)
+	return tenant + strings.TrimPrefix(local, c.BucketPrefix)
+}
+
+// unprefixedUserId returns the user ID as configured, without the user_prefix
+// prepended by prepend_prefix
+func (c *RgwClient) unprefixedUserId(userId string) string {
+	if !c.PrependPrefix {
+		return userId
+	}
+	tenant, local := splitTenant(userId, userTenantSeparators)
+	return tenant + strings.TrimPrefix(local, c.UserPrefix)
+}
+
+// planPrefix checks that the name in an attribute of the planned resource, or
+// of the prior state on destroy, starts with the prefix after its tenant
+func (c *RgwClient) planPrefix(ctx context.Context, req resource.ModifyPlanRequest, attribute string, option string, prefix string, separators string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if prefix == "" {
+		return diags
+	}
+
+	var value types.String
+	if req.Plan.Raw.IsNull() {
+		diags.Append(req.State.GetAttribute(ctx, path.Root(attribute), &value)...)
+	} else {
+		diags.Append(req.Plan.GetAttribute(ctx, path.Root(attribute), &value)...)
+	}
+	if diags.HasError() || value.IsUnknown() || value.IsNull() {
+		return diags
+	}
+
+	if _, local := splitTenant(value.ValueString(), separators); !strings.HasPrefix(local, prefix) {
+		hint := ""
+		if c.PrependPrefix {
+			hint = " Reference the id of the rgw_user or rgw_bucket resource, which includes the prepended prefix."
+		}
+		diags.AddAttributeError(path.Root(attribute), "name without prefix",
+			fmt.Sprintf("The name '%s' does not start with %s '%s' of the provider.%s", value.ValueString(), option, prefix, hint))
+	}
+
+	return diags
+}
+
+// planBucketPrefix checks the bucket name in an attribute against bucket_prefix
+func (c *RgwClient) planBucketPrefix(ctx context.Context, req resource.ModifyPlanRequest, attribute string) diag.Diagnostics {
+	return c.planPrefix(ctx, req, attribute, "bucket_prefix", c.BucketPrefix, bucketTenantSeparators)
+}
+
+// planUserPrefix checks the user ID in an attribute against user_prefix
+func (c *RgwClient) planUserPrefix(ctx context.Context, req resource.ModifyPlanRequest, attribute string) diag.Diagnostics {
+	return c.planPrefix(ctx, req, attribute, "user_prefix", c.UserPrefix, userTenantSeparators)
+}
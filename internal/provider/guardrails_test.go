@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+func TestPrependPrefix(t *testing.T) {
+	client := &RgwClient{UserPrefix: "ws1-", BucketPrefix: "ws1-", PrependPrefix: true}
+
+	buckets := map[string]string{
+		"app":          "ws1-app",
+		"ws1-app":      "ws1-app",
+		"tenant/app":   "tenant/ws1-app",
+		"tenant:app":   "tenant:ws1-app",
+		"tenant/ws1-a": "tenant/ws1-a",
+	}
+	for configured, expected := range buckets {
+		if prefixed := client.prefixedBucket(configured); prefixed != expected {
+			t.Errorf("expected bucket %s for %s, got %s", expected, configured, prefixed)
+		}
+	}
+	if unprefixed := client.unprefixedBucket("tenant/ws1-app"); unprefixed != "tenant/app" {
+		t.Errorf("expected bucket tenant/app, got %s", unprefixed)
+	}
+
+	if prefixed := client.prefixedUserId("tenant$alice"); prefixed != "tenant$ws1-alice" {
+		t.Errorf("expected user tenant$ws1-alice, got %s", prefixed)
+	}
+	if unprefixed := client.unprefixedUserId("ws1-alice"); unprefixed != "alice" {
+		t.Errorf("expected user alice, got %s", unprefixed)
+	}
+
+	// names are used as configured without prepend_prefix
+	client.PrependPrefix = false
+	if prefixed := client.prefixedBucket("app"); prefixed != "app" {
+		t.Errorf("expected bucket app, got %s", prefixed)
+	}
+}
+
+func TestS3KeysOfUser(t *testing.T) {
+	user := admin.User{
+		ID: "tenant$alice",
+		Keys: []admin.UserKeySpec{
+			{User: "tenant$alice", AccessKey: "KEY1"},
+			{User: "tenant$alice:swift", AccessKey: "SUBKEY"},
+			{User: "tenant$alice", AccessKey: "KEY2"},
+		},
+	}
+
+	if keys := s3KeysOfUser(user, ""); len(keys) != 2 || keys[0] != "KEY1" || keys[1] != "KEY2" {
+		t.Errorf("expected keys KEY1 and KEY2, got %v", keys)
+	}
+	// the replaced key doesn't count
+	if keys := s3KeysOfUser(user, "KEY1"); len(keys) != 1 || keys[0] != "KEY2" {
+		t.Errorf("expected key KEY2, got %v", keys)
+	}
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+// iamCall sends a signed request to the iam compatible api of rgw
+func (c *RgwClient) iamCall(ctx context.Context, action string, args url.Values) ([]byte, error) {
+	return c.queryCall(ctx, "iam", "2010-05-08", action, args)
+}
+
+// snsCall sends a signed request to the sns compatible topic api of rgw
+func (c *RgwClient) snsCall(ctx context.Context, action string, args url.Values) ([]byte, error) {
+	return c.queryCall(ctx, "sns", "2010-03-31", action, args)
+}
+
+// queryCall sends a signed request to one of the aws query style apis of rgw
+func (c *RgwClient) queryCall(ctx context.Context, service string, version string, action string, args url.Values) ([]byte, error) {
+	if args == nil {
+		args = url.Values{}
+	}
+	args.Set("Action", action)
+	args.Set("Version", version)
+	body := []byte(args.Encode())
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Admin.Endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	signer := v4.NewSigner(credentials.NewStaticCredentials(c.Admin.AccessKey, c.Admin.SecretKey, ""))
+	_, err = signer.Sign(request, bytes.NewReader(body), service, "default", time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Admin.HTTPClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		apiErr := adminError{StatusCode: resp.StatusCode}
+		errResp := struct {
+			Code      string `xml:"Error>Code"`
+			Message   string `xml:"Error>Message"`
+			RequestId string `xml:"RequestId"`
+		}{}
+		if err := xml.Unmarshal(respBody, &errResp); err != nil {
+			apiErr.Code = strings.TrimSpace(string(respBody))
+		} else {
+			apiErr.Code = errResp.Code
+			apiErr.Message = errResp.Message
+			apiErr.RequestId = errResp.RequestId
+		}
+		return nil, apiErr
+	}
+
+	return respBody, nil
+}
+
+// oidcProvider is an openid connect provider registered in rgw
+type oidcProvider struct {
+	Arn            string
+	Url            string   `xml:"GetOpenIDConnectProviderResult>Url"`
+	ClientIDList   []string `xml:"GetOpenIDConnectProviderResult>ClientIDList>member"`
+	ThumbprintList []string `xml:"GetOpenIDConnectProviderResult>ThumbprintList>member"`
+}
+
+// listOidcProviders lists the arns of all openid connect providers of the tenant of the admin user
+func (c *RgwClient) listOidcProviders(ctx context.Context) ([]string, error) {
+	body, err := c.iamCall(ctx, "ListOpenIDConnectProviders", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	list := struct {
+		Arns []string `xml:"ListOpenIDConnectProvidersResult>OpenIDConnectProviderList>member>Arn"`
+	}{}
+	if err := xml.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+
+	return list.Arns, nil
+}
+
+// getOidcProvider gets an openid connect provider by its arn
+func (c *RgwClient) getOidcProvider(ctx context.Context, arn string) (*oidcProvider, error) {
+	body, err := c.iamCall(ctx, "GetOpenIDConnectProvider", url.Values{"OpenIDConnectProviderArn": []string{arn}})
+	if err != nil {
+		return nil, err
+	}
+
+	provider := &oidcProvider{Arn: arn}
+	if err := xml.Unmarshal(body, provider); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
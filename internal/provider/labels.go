@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"encoding/json"
+	"sort"
+
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// labelKeys returns the keys of default_labels in sorted order
+func (c *RgwClient) labelKeys() []string {
+	keys := make([]string, 0, len(c.DefaultLabels))
+	for k := range c.DefaultLabels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// labelTags merges default_labels into a tag set, configured tags take
+// precedence, sorted by key
+func (c *RgwClient) labelTags(tags map[string]string) []s3types.Tag {
+	merged := make(map[string]string, len(c.DefaultLabels)+len(tags))
+	for k, v := range c.DefaultLabels {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+
+	keys := make([]string, 0, len(merged))
+	for k := range merged {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tagSet := make([]s3types.Tag, len(keys))
+	for i, k := range keys {
+		tagSet[i] = s3types.Tag{Key: aws.String(k), Value: aws.String(merged[k])}
+	}
+	return tagSet
+}
+
+// isLabel checks whether a tag was stamped from default_labels
+func (c *RgwClient) isLabel(key string, value string) bool {
+	label, ok := c.DefaultLabels[key]
+	return ok && label == value
+}
+
+// labelsOpaqueData returns default_labels encoded as opaque data of topics,
+// empty without labels
+func (c *RgwClient) labelsOpaqueData() string {
+	if len(c.DefaultLabels) == 0 {
+		return ""
+	}
+	// maps are encoded with sorted keys
+	b, _ := json.Marshal(c.DefaultLabels)
+	return string(b)
+}
@@ -0,0 +1,31 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestLabelTags(t *testing.T) {
+	client := &RgwClient{DefaultLabels: map[string]string{"workspace": "prod", "owner": "sre"}}
+
+	// configured tags take precedence over labels
+	tagSet := client.labelTags(map[string]string{"owner": "web", "app": "shop"})
+	expected := []string{"app=shop", "owner=web", "workspace=prod"}
+	if len(tagSet) != len(expected) {
+		t.Fatalf("expected tags %v, got %d tags", expected, len(tagSet))
+	}
+	for i, tag := range tagSet {
+		if got := aws.StringValue(tag.Key) + "=" + aws.StringValue(tag.Value); got != expected[i] {
+			t.Errorf("expected tag %s, got %s", expected[i], got)
+		}
+	}
+
+	if !client.isLabel("workspace", "prod") || client.isLabel("owner", "web") {
+		t.Error("expected only unchanged labels to be detected")
+	}
+
+	if opaque := client.labelsOpaqueData(); opaque != `{"owner":"sre","workspace":"prod"}` {
+		t.Errorf("unexpected opaque data %s", opaque)
+	}
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = &MetadataDataSource{}
+
+func NewMetadataDataSource() datasource.DataSource {
+	return &MetadataDataSource{}
+}
+
+type MetadataDataSource struct {
+	client *RgwClient
+}
+
+type MetadataDataSourceModel struct {
+	Id      types.String `tfsdk:"id"`
+	Section types.String `tfsdk:"section"`
+	Key     types.String `tfsdk:"key"`
+	Json    types.String `tfsdk:"json"`
+}
+
+func (d *MetadataDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_metadata"
+}
+
+func (d *MetadataDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Metadata entry of a user or bucket in the format of `radosgw-admin metadata get`, which can be restored with `radosgw-admin metadata put`",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"section": schema.StringAttribute{
+				MarkdownDescription: "Metadata section, one of `user`, `bucket` or `bucket.instance`",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("user", "bucket", "bucket.instance"),
+				},
+			},
+			"key": schema.StringAttribute{
+				MarkdownDescription: "Key of the entry, e.g. the user ID (`tenant$username`), the bucket name (`tenant/bucket`) or the bucket instance (`bucket:instance_id`)",
+				Required:            true,
+			},
+			"json": schema.StringAttribute{
+				MarkdownDescription: "The metadata entry as indented JSON. User entries contain the secret keys of the user.",
+				Computed:            true,
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (d *MetadataDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "data.rgw_metadata")...)
+}
+
+func (d *MetadataDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *MetadataDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// get the raw entry to keep the field order of radosgw-admin
+	body, err := d.client.adminCall(ctx, http.MethodGet, "/metadata/"+data.Section.ValueString(), url.Values{"key": []string{data.Key.ValueString()}}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError("could not get metadata", err.Error())
+		return
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "    "); err != nil {
+		resp.Diagnostics.AddError("could not decode metadata", err.Error())
+		return
+	}
+
+	data.Id = types.StringValue(fmt.Sprintf("%s:%s", data.Section.ValueString(), data.Key.ValueString()))
+	data.Json = types.StringValue(indented.String())
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
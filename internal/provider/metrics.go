@@ -0,0 +1,229 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+// metricsOutputLog writes the metrics to the provider log instead of a file
+const metricsOutputLog = "log"
+
+// apiMetrics counts the api requests sent by the provider instances sharing a
+// metrics_output
+type apiMetrics struct {
+	output string
+
+	lock     sync.Mutex
+	requests map[apiMetricKey]int64
+	retries  map[apiMetricKey]int64
+	count    map[apiMetricKey]int64
+	seconds  map[apiMetricKey]float64
+}
+
+// apiMetricKey are the labels of a metric, Code is only set for requests
+type apiMetricKey struct {
+	Api       string
+	Operation string
+	Code      string
+}
+
+var (
+	// configuredMetrics are the metrics by metrics_output of all configured
+	// provider instances of this process
+	configuredMetrics     = map[string]*apiMetrics{}
+	configuredMetricsLock sync.Mutex
+)
+
+// metricsFor returns the metrics written to the output, shared by all provider
+// instances configured with it
+func metricsFor(output string) *apiMetrics {
+	configuredMetricsLock.Lock()
+	defer configuredMetricsLock.Unlock()
+
+	m, ok := configuredMetrics[output]
+	if !ok {
+		m = &apiMetrics{
+			output:   output,
+			requests: map[apiMetricKey]int64{},
+			retries:  map[apiMetricKey]int64{},
+			count:    map[apiMetricKey]int64{},
+			seconds:  map[apiMetricKey]float64{},
+		}
+		configuredMetrics[output] = m
+	}
+	return m
+}
+
+// WriteMetrics writes the metrics of all provider instances with
+// metrics_output, to be called once Terraform shuts the provider down
+func WriteMetrics() {
+	configuredMetricsLock.Lock()
+	defer configuredMetricsLock.Unlock()
+
+	for _, m := range configuredMetrics {
+		if err := m.write(); err != nil {
+			log.Printf("[ERROR] could not write metrics to %s: %s", m.output, err)
+		}
+	}
+}
+
+// observe records a request, retries are the attempts after the first one
+func (m *apiMetrics) observe(api string, operation string, code string, retry bool, duration time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.requests[apiMetricKey{api, operation, code}]++
+	if retry {
+		m.retries[apiMetricKey{api, operation, ""}]++
+	}
+	m.count[apiMetricKey{api, operation, ""}]++
+	m.seconds[apiMetricKey{api, operation, ""}] += duration.Seconds()
+}
+
+// write writes the metrics to the file, replacing it atomically so collectors
+// never read a partial file, or to the log
+func (m *apiMetrics) write() error {
+	var buf bytes.Buffer
+	m.format(&buf)
+
+	if m.output == metricsOutputLog {
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			log.Printf("[INFO] %s", line)
+		}
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(m.output), filepath.Base(m.output)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), m.output)
+}
+
+// format writes the metrics in the prometheus text format, sorted by labels
+func (m *apiMetrics) format(w io.Writer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	fmt.Fprintln(w, "# HELP rgw_provider_api_requests_total Requests sent to the RGW apis by status code, error on transport errors.")
+	fmt.Fprintln(w, "# TYPE rgw_provider_api_requests_total counter")
+	for _, k := range sortedMetricKeys(m.requests) {
+		fmt.Fprintf(w, "rgw_provider_api_requests_total{api=%q,operation=%q,code=%q} %d\n", k.Api, k.Operation, k.Code, m.requests[k])
+	}
+
+	fmt.Fprintln(w, "# HELP rgw_provider_api_retries_total Requests retried after a failed attempt.")
+	fmt.Fprintln(w, "# TYPE rgw_provider_api_retries_total counter")
+	for _, k := range sortedMetricKeys(m.retries) {
+		fmt.Fprintf(w, "rgw_provider_api_retries_total{api=%q,operation=%q} %d\n", k.Api, k.Operation, m.retries[k])
+	}
+
+	fmt.Fprintln(w, "# HELP rgw_provider_api_request_duration_seconds Time until the response headers of requests were received.")
+	fmt.Fprintln(w, "# TYPE rgw_provider_api_request_duration_seconds summary")
+	for _, k := range sortedMetricKeys(m.count) {
+		fmt.Fprintf(w, "rgw_provider_api_request_duration_seconds_sum{api=%q,operation=%q} %s\n", k.Api, k.Operation, strconv.FormatFloat(m.seconds[k], 'f', -1, 64))
+		fmt.Fprintf(w, "rgw_provider_api_request_duration_seconds_count{api=%q,operation=%q} %d\n", k.Api, k.Operation, m.count[k])
+	}
+}
+
+// sortedMetricKeys returns the keys of a metric sorted by their labels
+func sortedMetricKeys(values map[apiMetricKey]int64) []apiMetricKey {
+	keys := make([]apiMetricKey, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Api != keys[j].Api {
+			return keys[i].Api < keys[j].Api
+		}
+		if keys[i].Operation != keys[j].Operation {
+			return keys[i].Operation < keys[j].Operation
+		}
+		return keys[i].Code < keys[j].Code
+	})
+	return keys
+}
+
+// metricsClient records the requests of the admin, iam and sns apis, and of
+// the s3 api when used as http client of the aws sdk
+type metricsClient struct {
+	next    admin.HTTPClient
+	metrics *apiMetrics
+}
+
+func (c *metricsClient) Do(req *http.Request) (*http.Response, error) {
+	api, operation := requestOperation(req)
+	// the aws sdk numbers its attempts, e.g. "attempt=2; max=3"
+	retry := strings.HasPrefix(req.Header.Get("amz-sdk-request"), "attempt=") &&
+		!strings.HasPrefix(req.Header.Get("amz-sdk-request"), "attempt=1;")
+
+	start := time.Now()
+	resp, err := c.next.Do(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	c.metrics.observe(api, operation, code, retry, time.Since(start))
+
+	return resp, err
+}
+
+// requestOperation names the api and operation of a request: the admin
+// endpoint with its method, the action of iam and sns requests or the
+// operation of the aws sdk
+func requestOperation(req *http.Request) (string, string) {
+	if operation := awsmiddleware.GetOperationName(req.Context()); operation != "" {
+		return "s3", operation
+	}
+
+	if i := strings.Index(req.URL.Path, "/admin/"); i >= 0 {
+		endpoint := strings.SplitN(req.URL.Path[i+len("/admin/"):], "/", 2)[0]
+		return "admin", req.Method + " " + endpoint
+	}
+
+	// query apis are signed for their service, e.g. Credential=key/date/default/iam/aws4_request
+	api := "unknown"
+	if i := strings.Index(req.Header.Get("Authorization"), "Credential="); i >= 0 {
+		if scope := strings.Split(req.Header.Get("Authorization")[i:], "/"); len(scope) >= 4 {
+			api = scope[3]
+		}
+	}
+	operation := "unknown"
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			defer body.Close()
+			if b, err := io.ReadAll(body); err == nil {
+				if args, err := url.ParseQuery(string(b)); err == nil && args.Get("Action") != "" {
+					operation = args.Get("Action")
+				}
+			}
+		}
+	}
+	return api, operation
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMetricsClient(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/admin/metadata/user" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"Code":"NoSuchKey"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`<GetRoleResponse><GetRoleResult><Role><RoleName>ci</RoleName></Role></GetRoleResult></GetRoleResponse>`))
+	})
+	metrics := &apiMetrics{
+		requests: map[apiMetricKey]int64{},
+		retries:  map[apiMetricKey]int64{},
+		count:    map[apiMetricKey]int64{},
+		seconds:  map[apiMetricKey]float64{},
+	}
+	client.Admin.HTTPClient = &metricsClient{next: client.Admin.HTTPClient, metrics: metrics}
+
+	if _, err := client.userExists(context.Background(), "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.getRole(context.Background(), "ci"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.iamCall(context.Background(), "GetRole", url.Values{"RoleName": []string{"ci"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	metrics.format(&buf)
+	for _, expected := range []string{
+		`rgw_provider_api_requests_total{api="admin",operation="GET metadata",code="404"} 1`,
+		`rgw_provider_api_requests_total{api="iam",operation="GetRole",code="200"} 2`,
+		`rgw_provider_api_request_duration_seconds_count{api="iam",operation="GetRole"} 2`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected metrics to contain %s, got:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestMetricsWriteFile(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "rgw.prom")
+	metrics := metricsFor(output)
+	t.Cleanup(func() {
+		configuredMetricsLock.Lock()
+		delete(configuredMetrics, output)
+		configuredMetricsLock.Unlock()
+	})
+	metrics.observe("s3", "PutBucketPolicy", "503", false, 0)
+	metrics.observe("s3", "PutBucketPolicy", "200", true, 0)
+
+	WriteMetrics()
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `rgw_provider_api_retries_total{api="s3",operation="PutBucketPolicy"} 1`) {
+		t.Errorf("expected a retry, got:\n%s", b)
+	}
+	if !strings.Contains(string(b), `rgw_provider_api_request_duration_seconds_count{api="s3",operation="PutBucketPolicy"} 2`) {
+		t.Errorf("expected two requests, got:\n%s", b)
+	}
+}
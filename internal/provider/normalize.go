@@ -0,0 +1,18 @@
+package provider
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalizeDisplayName normalizes a display name the way it is compared by
+// the provider: unicode NFC, trimmed and with collapsed whitespace
+func normalizeDisplayName(s string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(s)), " ")
+}
+
+// normalizeEmail normalizes an email address, rgw stores them lowercase
+func normalizeEmail(s string) string {
+	return strings.ToLower(strings.TrimSpace(norm.NFC.String(s)))
+}
@@ -0,0 +1,319 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &ObjectCopyResource{}
+var _ resource.ResourceWithModifyPlan = &ObjectCopyResource{}
+
+func NewObjectCopyResource() resource.Resource {
+	return &ObjectCopyResource{}
+}
+
+type ObjectCopyResource struct {
+	client *RgwClient
+}
+
+type ObjectCopyResourceModel struct {
+	Id           types.String `tfsdk:"id"`
+	Bucket       types.String `tfsdk:"bucket"`
+	Key          types.String `tfsdk:"key"`
+	SourceBucket types.String `tfsdk:"source_bucket"`
+	SourceKey    types.String `tfsdk:"source_key"`
+	ContentType  types.String `tfsdk:"content_type"`
+	Metadata     types.Map    `tfsdk:"metadata"`
+	Etag         types.String `tfsdk:"etag"`
+	SourceEtag   types.String `tfsdk:"source_etag"`
+	VersionId    types.String `tfsdk:"version_id"`
+}
+
+func (r *ObjectCopyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_object_copy"
+}
+
+func (r *ObjectCopyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	requiredName := func(description string) schema.StringAttribute {
+		return schema.StringAttribute{
+			MarkdownDescription: description,
+			Required:            true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
+			},
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Server-side copy of an object, e.g. to promote configuration objects between environments without downloading them. The copy is recreated when the source object or the copy change outside of Terraform, and deleted on destroy.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"bucket":        requiredName("Bucket Name of the copy"),
+			"key":           requiredName("Object key of the copy"),
+			"source_bucket": requiredName("Bucket Name of the source object, qualified with its tenant (`tenant:bucket`) to copy across tenants if the bucket policy of the source permits it"),
+			"source_key":    requiredName("Object key of the source object"),
+			"content_type": schema.StringAttribute{
+				MarkdownDescription: "Content type of the copy. Setting `content_type` or `metadata` replaces all metadata of the source object.",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"metadata": schema.MapAttribute{
+				MarkdownDescription: "User metadata of the copy, without the `x-amz-meta-` prefix. Setting `content_type` or `metadata` replaces all metadata of the source object.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"etag": schema.StringAttribute{
+				MarkdownDescription: "ETag of the copy",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"source_etag": schema.StringAttribute{
+				MarkdownDescription: "ETag of the source object at the time of the copy",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"version_id": schema.StringAttribute{
+				MarkdownDescription: "Version ID of the copy if versioning is enabled on its bucket",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *ObjectCopyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_object_copy")...)
+}
+
+func (r *ObjectCopyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenants against allowed_tenants and bucket against bucket_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "bucket", tenantOfBucket)...)
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "source_bucket", tenantOfBucket)...)
+	resp.Diagnostics.Append(r.client.planBucketPrefix(ctx, req, "bucket")...)
+}
+
+func (r *ObjectCopyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("copy object")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *ObjectCopyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	s3req := &s3.CopyObjectInput{
+		Bucket:     aws.String(data.Bucket.ValueString()),
+		Key:        aws.String(data.Key.ValueString()),
+		CopySource: aws.String(copySource(data.SourceBucket.ValueString(), data.SourceKey.ValueString())),
+	}
+	if !data.ContentType.IsNull() || !data.Metadata.IsNull() {
+		s3req.MetadataDirective = s3types.MetadataDirectiveReplace
+		if !data.ContentType.IsNull() {
+			s3req.ContentType = aws.String(data.ContentType.ValueString())
+		}
+		if !data.Metadata.IsNull() {
+			resp.Diagnostics.Append(data.Metadata.ElementsAs(ctx, &s3req.Metadata, false)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("copy object %s/%s to %s/%s", data.SourceBucket.ValueString(), data.SourceKey.ValueString(), data.Bucket.ValueString(), data.Key.ValueString()))
+	s3res, err := r.client.S3.CopyObject(ctx, s3req)
+	if err != nil {
+		resp.Diagnostics.AddError("could not copy object", err.Error())
+		return
+	}
+
+	// the etag of the copy equals the one of the source unless it was encrypted
+	source, err := r.client.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(data.SourceBucket.ValueString()),
+		Key:    aws.String(data.SourceKey.ValueString()),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("could not get source object", err.Error())
+		return
+	}
+
+	data.Id = types.StringValue(fmt.Sprintf("%s/%s", data.Bucket.ValueString(), data.Key.ValueString()))
+	data.Etag = types.StringValue("")
+	if s3res.CopyObjectResult != nil {
+		data.Etag = types.StringValue(aws.StringValue(s3res.CopyObjectResult.ETag))
+	}
+	data.SourceEtag = types.StringValue(aws.StringValue(source.ETag))
+	data.VersionId = types.StringValue(aws.StringValue(s3res.VersionId))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ObjectCopyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *ObjectCopyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// recreate a deleted or overwritten copy
+	head, err := r.client.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+		Key:    aws.String(data.Key.ValueString()),
+	})
+	if err != nil {
+		// HeadObject has no body, so a missing object is reported as NotFound
+		var notFound *s3types.NotFound
+		if errors.As(err, &notFound) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get object", err.Error())
+		return
+	}
+	if aws.StringValue(head.ETag) != data.Etag.ValueString() {
+		tflog.Info(ctx, fmt.Sprintf("object %s changed, copy it again", data.Id.ValueString()))
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// copy a changed source again, keep the copy of a deleted source
+	source, err := r.client.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(data.SourceBucket.ValueString()),
+		Key:    aws.String(data.SourceKey.ValueString()),
+	})
+	if err != nil {
+		var notFound *s3types.NotFound
+		if !errors.As(err, &notFound) {
+			resp.Diagnostics.AddError("could not get source object", err.Error())
+			return
+		}
+	} else if aws.StringValue(source.ETag) != data.SourceEtag.ValueString() {
+		tflog.Info(ctx, fmt.Sprintf("source of object %s changed, copy it again", data.Id.ValueString()))
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ObjectCopyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update object copy")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *ObjectCopyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Currently there is nothing to update in place, all attributes require replacement
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ObjectCopyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete object copy")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *ObjectCopyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// deleting a missing object succeeds
+	_, err := r.client.S3.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+		Key:    aws.String(data.Key.ValueString()),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("could not delete object copy", err.Error())
+		return
+	}
+}
+
+// copySource returns the url encoded x-amz-copy-source of an object
+func copySource(bucket string, key string) string {
+	segments := strings.Split(key, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return bucket + "/" + strings.Join(segments, "/")
+}
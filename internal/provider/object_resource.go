@@ -0,0 +1,391 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &ObjectResource{}
+var _ resource.ResourceWithModifyPlan = &ObjectResource{}
+
+func NewObjectResource() resource.Resource {
+	return &ObjectResource{}
+}
+
+type ObjectResource struct {
+	client *RgwClient
+}
+
+type ObjectResourceModel struct {
+	Id          types.String `tfsdk:"id"`
+	Bucket      types.String `tfsdk:"bucket"`
+	Key         types.String `tfsdk:"key"`
+	Content     types.String `tfsdk:"content"`
+	Source      types.String `tfsdk:"source"`
+	ContentType types.String `tfsdk:"content_type"`
+	Metadata    types.Map    `tfsdk:"metadata"`
+	Overwrite   types.Bool   `tfsdk:"overwrite"`
+	Etag        types.String `tfsdk:"etag"`
+	VersionId   types.String `tfsdk:"version_id"`
+}
+
+func (r *ObjectResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_object"
+}
+
+func (r *ObjectResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Small object uploaded from a string or a local file, e.g. to bootstrap configuration files in buckets. The object is uploaded again when its content or the object change, and deleted on destroy.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Bucket Name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"key": schema.StringAttribute{
+				MarkdownDescription: "Object key",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"content": schema.StringAttribute{
+				MarkdownDescription: "Content of the object. Exactly one of `content` and `source` has to be set.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("source")),
+				},
+			},
+			"source": schema.StringAttribute{
+				MarkdownDescription: "Path of a local file uploaded as content of the object. Exactly one of `content` and `source` has to be set.",
+				Optional:            true,
+			},
+			"content_type": schema.StringAttribute{
+				MarkdownDescription: "Content type of the object",
+				Optional:            true,
+			},
+			"metadata": schema.MapAttribute{
+				MarkdownDescription: "User metadata of the object, without the `x-amz-meta-` prefix",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"overwrite": schema.BoolAttribute{
+				MarkdownDescription: "Whether to overwrite an existing object. If `false`, the object is only uploaded if the key does not exist yet (`If-None-Match: *`), and is never uploaded again, so objects changed after bootstrapping are kept. Defaults to `true`.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Bool{
+					boolDefaultModifier{true},
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"etag": schema.StringAttribute{
+				MarkdownDescription: "ETag of the object, the MD5 hash of its content",
+				Computed:            true,
+			},
+			"version_id": schema.StringAttribute{
+				MarkdownDescription: "Version ID of the object if versioning is enabled on its bucket",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *ObjectResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_object")...)
+}
+
+func (r *ObjectResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and bucket against bucket_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "bucket", tenantOfBucket)...)
+	resp.Diagnostics.Append(r.client.planBucketPrefix(ctx, req, "bucket")...)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var data, state *ObjectResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// an object which is not overwritten keeps its etag once uploaded, and
+	// may exist with another etag before
+	if !data.Overwrite.ValueBool() {
+		if state != nil {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("etag"), state.Etag)...)
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("version_id"), state.VersionId)...)
+		}
+		return
+	}
+
+	// plan the etag of the content, so changed content or objects are uploaded again
+	if data.Content.IsUnknown() || data.Source.IsUnknown() {
+		return
+	}
+	content, err := objectContent(data)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("source"), "could not read source", err.Error())
+		return
+	}
+	etag := objectEtag(content)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("etag"), etag)...)
+	if state != nil && state.Etag.ValueString() == etag && state.ContentType.Equal(data.ContentType) && state.Metadata.Equal(data.Metadata) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("version_id"), state.VersionId)...)
+	}
+}
+
+func (r *ObjectResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("upload object")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *ObjectResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.putObject(ctx, data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Id = types.StringValue(fmt.Sprintf("%s/%s", data.Bucket.ValueString(), data.Key.ValueString()))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ObjectResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *ObjectResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// upload a deleted object again
+	head, err := r.client.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+		Key:    aws.String(data.Key.ValueString()),
+	})
+	if err != nil {
+		// HeadObject has no body, so a missing object is reported as NotFound
+		var notFound *s3types.NotFound
+		if errors.As(err, &notFound) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get object", err.Error())
+		return
+	}
+
+	// a changed etag is planned to be uploaded again
+	data.Etag = types.StringValue(aws.StringValue(head.ETag))
+	data.VersionId = types.StringValue(aws.StringValue(head.VersionId))
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_object", req.State, resp.State)...)
+}
+
+func (r *ObjectResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update object")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *ObjectResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state *ObjectResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// upload changed objects again, unless they must not be overwritten
+	if data.Overwrite.ValueBool() && (!data.Etag.Equal(state.Etag) || !data.ContentType.Equal(state.ContentType) || !data.Metadata.Equal(state.Metadata)) {
+		resp.Diagnostics.Append(r.putObject(ctx, data)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	} else {
+		data.Etag = state.Etag
+		data.VersionId = state.VersionId
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ObjectResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete object")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *ObjectResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// deleting a missing object succeeds
+	_, err := r.client.S3.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+		Key:    aws.String(data.Key.ValueString()),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("could not delete object", err.Error())
+		return
+	}
+}
+
+// putObject uploads the content of the object and sets its etag and version,
+// or reads them from the existing object if it must not be overwritten
+func (r *ObjectResource) putObject(ctx context.Context, data *ObjectResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	content, err := objectContent(data)
+	if err != nil {
+		diags.AddAttributeError(path.Root("source"), "could not read source", err.Error())
+		return diags
+	}
+
+	s3req := &s3.PutObjectInput{
+		Bucket: aws.String(data.Bucket.ValueString()),
+		Key:    aws.String(data.Key.ValueString()),
+		Body:   bytes.NewReader(content),
+	}
+	if !data.ContentType.IsNull() {
+		s3req.ContentType = aws.String(data.ContentType.ValueString())
+	}
+	if !data.Metadata.IsNull() {
+		diags.Append(data.Metadata.ElementsAs(ctx, &s3req.Metadata, false)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	// only create the object if it doesn't exist, the sdk has no field for it yet
+	var optFns []func(*s3.Options)
+	if !data.Overwrite.ValueBool() {
+		optFns = append(optFns, func(o *s3.Options) {
+			o.APIOptions = append(o.APIOptions, smithyhttp.AddHeaderValue("If-None-Match", "*"))
+		})
+	}
+
+	s3res, err := r.client.S3.PutObject(ctx, s3req, optFns...)
+	if err != nil {
+		var ae smithy.APIError
+		if !errors.As(err, &ae) || ae.ErrorCode() != "PreconditionFailed" {
+			diags.AddError("could not upload object", err.Error())
+			return diags
+		}
+
+		tflog.Info(ctx, fmt.Sprintf("object %s/%s exists, keep it", data.Bucket.ValueString(), data.Key.ValueString()))
+		head, err := r.client.S3.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(data.Bucket.ValueString()),
+			Key:    aws.String(data.Key.ValueString()),
+		})
+		if err != nil {
+			diags.AddError("could not get object", err.Error())
+			return diags
+		}
+		data.Etag = types.StringValue(aws.StringValue(head.ETag))
+		data.VersionId = types.StringValue(aws.StringValue(head.VersionId))
+		return diags
+	}
+
+	data.Etag = types.StringValue(aws.StringValue(s3res.ETag))
+	data.VersionId = types.StringValue(aws.StringValue(s3res.VersionId))
+	return diags
+}
+
+// objectContent returns the content of the object from content or source
+func objectContent(data *ObjectResourceModel) ([]byte, error) {
+	if !data.Source.IsNull() {
+		return os.ReadFile(data.Source.ValueString())
+	}
+	return []byte(data.Content.ValueString()), nil
+}
+
+// objectEtag returns the etag of an object uploaded in a single part, the
+// quoted MD5 hash of its content
+func objectEtag(content []byte) string {
+	sum := md5.Sum(content)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestObjectPutIfAbsent(t *testing.T) {
+	var ifNoneMatch []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+			if r.Header.Get("If-None-Match") == "*" {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				_, _ = w.Write([]byte(`<Error><Code>PreconditionFailed</Code></Error>`))
+				return
+			}
+			w.Header().Set("ETag", objectEtag([]byte("seed")))
+		case http.MethodHead:
+			w.Header().Set("ETag", `"existing"`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	client.S3Endpoint = client.Admin.Endpoint
+	client.ForcePathStyle = true
+	client.S3 = client.newS3Client("access", "secret")
+	r := &ObjectResource{client: client}
+
+	data := &ObjectResourceModel{
+		Bucket:    types.StringValue("config"),
+		Key:       types.StringValue("seed.json"),
+		Content:   types.StringValue("seed"),
+		Source:    types.StringNull(),
+		Metadata:  types.MapNull(types.StringType),
+		Overwrite: types.BoolValue(true),
+	}
+	if diags := r.putObject(context.Background(), data); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if data.Etag.ValueString() != objectEtag([]byte("seed")) {
+		t.Errorf("unexpected etag %s", data.Etag)
+	}
+
+	// an existing object is kept
+	data.Overwrite = types.BoolValue(false)
+	if diags := r.putObject(context.Background(), data); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if data.Etag.ValueString() != `"existing"` {
+		t.Errorf("expected etag of existing object, got %s", data.Etag)
+	}
+	if len(ifNoneMatch) != 2 || ifNoneMatch[0] != "" || ifNoneMatch[1] != "*" {
+		t.Errorf("unexpected If-None-Match headers %v", ifNoneMatch)
+	}
+}
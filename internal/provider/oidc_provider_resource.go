@@ -0,0 +1,285 @@
+package provider
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &OidcProviderResource{}
+var _ resource.ResourceWithModifyPlan = &OidcProviderResource{}
+var _ resource.ResourceWithImportState = &OidcProviderResource{}
+
+func NewOidcProviderResource() resource.Resource {
+	return &OidcProviderResource{}
+}
+
+type OidcProviderResource struct {
+	client *RgwClient
+}
+
+type OidcProviderResourceModel struct {
+	Id          types.String   `tfsdk:"id"`
+	Arn         types.String   `tfsdk:"arn"`
+	Url         types.String   `tfsdk:"url"`
+	ClientIds   []types.String `tfsdk:"client_ids"`
+	Thumbprints []types.String `tfsdk:"thumbprints"`
+}
+
+func (r *OidcProviderResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_oidc_provider"
+}
+
+func (r *OidcProviderResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "OpenID Connect provider, e.g. Keycloak or Dex, whose tokens can be exchanged for role credentials with `AssumeRoleWithWebIdentity`. Providers belong to the tenant of the provider credentials. rgw cannot update providers, every change replaces the provider under the same ARN.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"arn": schema.StringAttribute{
+				MarkdownDescription: "ARN of the provider, to be used as federated principal in role trust policies",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"url": schema.StringAttribute{
+				MarkdownDescription: "URL of the identity provider, e.g. `https://keycloak.example.com/realms/ceph`",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^https://.+`), "must be a https url"),
+				},
+			},
+			"client_ids": schema.ListAttribute{
+				MarkdownDescription: "Client IDs (audiences) accepted from the provider",
+				ElementType:         types.StringType,
+				Required:            true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(stringvalidator.LengthBetween(1, 255)),
+				},
+			},
+			"thumbprints": schema.ListAttribute{
+				MarkdownDescription: "SHA-1 thumbprints (40 hex characters) of the server certificates of the provider",
+				ElementType:         types.StringType,
+				Required:            true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.List{
+					listvalidator.SizeBetween(1, 5),
+					listvalidator.ValueStringsAre(stringvalidator.RegexMatches(regexp.MustCompile(`^[0-9a-fA-F]{40}$`), "must be 40 hex characters")),
+				},
+			},
+		},
+	}
+}
+
+func (r *OidcProviderResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_oidc_provider")...)
+}
+
+func (r *OidcProviderResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "url", tenantOfCredentials)...)
+}
+
+func (r *OidcProviderResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create oidc provider")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *OidcProviderResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	args := url.Values{"Url": []string{data.Url.ValueString()}}
+	for i, clientId := range stringsFromModel(data.ClientIds) {
+		args.Set(fmt.Sprintf("ClientIDList.member.%d", i+1), clientId)
+	}
+	for i, thumbprint := range stringsFromModel(data.Thumbprints) {
+		args.Set(fmt.Sprintf("ThumbprintList.member.%d", i+1), thumbprint)
+	}
+
+	body, err := r.client.iamCall(ctx, "CreateOpenIDConnectProvider", args)
+	if err != nil {
+		resp.Diagnostics.AddError("could not create oidc provider", err.Error())
+		return
+	}
+
+	result := struct {
+		Arn string `xml:"CreateOpenIDConnectProviderResult>OpenIDConnectProviderArn"`
+	}{}
+	if err := xml.Unmarshal(body, &result); err != nil {
+		resp.Diagnostics.AddError("could not parse created oidc provider", err.Error())
+		return
+	}
+
+	// use arn as resource id
+	data.Arn = types.StringValue(result.Arn)
+	data.Id = data.Arn
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *OidcProviderResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *OidcProviderResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	provider, err := r.client.getOidcProvider(ctx, data.Id.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get oidc provider", err.Error())
+		return
+	}
+
+	data.Arn = data.Id
+
+	// rgw stores the url without scheme, keep the configured url if it matches
+	if strings.TrimPrefix(data.Url.ValueString(), "https://") != strings.TrimPrefix(provider.Url, "https://") {
+		data.Url = types.StringValue(provider.Url)
+		if !strings.HasPrefix(provider.Url, "https://") {
+			data.Url = types.StringValue("https://" + provider.Url)
+		}
+	}
+
+	// keep the configured order if only the order differs
+	if !sameStrings(stringsFromModel(data.ClientIds), provider.ClientIDList) {
+		data.ClientIds = stringsToModel(provider.ClientIDList)
+	}
+	if !sameStrings(stringsFromModel(data.Thumbprints), provider.ThumbprintList) {
+		data.Thumbprints = stringsToModel(provider.ThumbprintList)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_oidc_provider", req.State, resp.State)...)
+}
+
+func (r *OidcProviderResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update oidc provider")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *OidcProviderResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Currently there is nothing to update in place, all attributes require replacement
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *OidcProviderResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete oidc provider")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *OidcProviderResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.iamCall(ctx, "DeleteOpenIDConnectProvider", url.Values{"OpenIDConnectProviderArn": []string{data.Id.ValueString()}})
+	if err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("could not delete oidc provider", err.Error())
+		return
+	}
+}
+
+func (r *OidcProviderResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// sameStrings checks whether two slices hold the same strings in any order
+func sameStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+	return true
+}
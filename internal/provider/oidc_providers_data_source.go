@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = &OidcProvidersDataSource{}
+
+func NewOidcProvidersDataSource() datasource.DataSource {
+	return &OidcProvidersDataSource{}
+}
+
+type OidcProvidersDataSource struct {
+	client *RgwClient
+}
+
+type OidcProvidersDataSourceModel struct {
+	Id        types.String        `tfsdk:"id"`
+	Providers []OidcProviderModel `tfsdk:"providers"`
+}
+
+type OidcProviderModel struct {
+	Arn         types.String `tfsdk:"arn"`
+	Url         types.String `tfsdk:"url"`
+	ClientIds   []string     `tfsdk:"client_ids"`
+	Thumbprints []string     `tfsdk:"thumbprints"`
+}
+
+func (d *OidcProvidersDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_oidc_providers"
+}
+
+func (d *OidcProvidersDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "OpenID Connect providers registered in the tenant of the provider credentials",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"providers": schema.ListNestedAttribute{
+				MarkdownDescription: "The registered providers, sorted by ARN",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"arn": schema.StringAttribute{
+							MarkdownDescription: "ARN of the provider, to be used as federated principal in role trust policies",
+							Computed:            true,
+						},
+						"url": schema.StringAttribute{
+							MarkdownDescription: "URL of the identity provider",
+							Computed:            true,
+						},
+						"client_ids": schema.ListAttribute{
+							MarkdownDescription: "Client IDs (audiences) accepted from the provider",
+							ElementType:         types.StringType,
+							Computed:            true,
+						},
+						"thumbprints": schema.ListAttribute{
+							MarkdownDescription: "Thumbprints of the server certificates of the provider",
+							ElementType:         types.StringType,
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *OidcProvidersDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "data.rgw_oidc_providers")...)
+}
+
+func (d *OidcProvidersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *OidcProvidersDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	arns, err := d.client.listOidcProviders(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("could not list oidc providers", err.Error())
+		return
+	}
+	sort.Strings(arns)
+
+	data.Providers = []OidcProviderModel{}
+	for _, arn := range arns {
+		provider, err := d.client.getOidcProvider(ctx, arn)
+		if err != nil {
+			resp.Diagnostics.AddError("could not get oidc provider", err.Error())
+			return
+		}
+
+		model := OidcProviderModel{
+			Arn:         types.StringValue(provider.Arn),
+			Url:         types.StringValue(provider.Url),
+			ClientIds:   provider.ClientIDList,
+			Thumbprints: provider.ThumbprintList,
+		}
+		if model.ClientIds == nil {
+			model.ClientIds = []string{}
+		}
+		if model.Thumbprints == nil {
+			model.Thumbprints = []string{}
+		}
+		data.Providers = append(data.Providers, model)
+	}
+
+	data.Id = types.StringValue("oidc_providers")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/smithy-go"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// policyParseErrorCodes are returned by rgw if its parser rejects a policy
+var policyParseErrorCodes = map[string]bool{
+	"MalformedPolicy":         true,
+	"MalformedPolicyDocument": true,
+}
+
+// isPolicyParseError checks whether rgw rejected a policy. InvalidArgument is
+// also returned for other invalid arguments, so it only counts if its message
+// is about the policy.
+func isPolicyParseError(code string, message string) bool {
+	if code == "InvalidArgument" {
+		return strings.Contains(strings.ToLower(message), "policy")
+	}
+	return policyParseErrorCodes[code]
+}
+
+// policyValidatedTag marks the canary role with the hash of the last valid
+// trust policy, so the policy isn't validated again when terraform plans the
+// resource once more at apply
+const policyValidatedTag = "terraform-provider-rgw:validated-policy"
+
+// validateBucketPolicy puts the policy on the canary bucket set with
+// policy_validation_bucket, so parse errors of rgw are reported at plan time
+func (c *RgwClient) validateBucketPolicy(ctx context.Context, attribute path.Path, policy string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if c.PolicyValidationBucket == "" || c.ReadOnly {
+		return diags
+	}
+
+	_, err := c.S3.PutBucketPolicy(ctx, &s3.PutBucketPolicyInput{
+		Bucket: aws.String(c.PolicyValidationBucket),
+		Policy: aws.String(policy),
+	})
+	if err != nil {
+		var ae smithy.APIError
+		if errors.As(err, &ae) && isPolicyParseError(ae.ErrorCode(), ae.ErrorMessage()) {
+			diags.AddAttributeError(attribute, "invalid policy", fmt.Sprintf("RGW rejected the policy on the validation bucket '%s': %s", c.PolicyValidationBucket, ae.ErrorMessage()))
+			return diags
+		}
+		diags.AddWarning("could not validate policy", fmt.Sprintf("could not put the policy on the validation bucket '%s': %s", c.PolicyValidationBucket, err.Error()))
+		return diags
+	}
+
+	// leave the canary bucket without policy again
+	_, err = c.S3.DeleteBucketPolicy(ctx, &s3.DeleteBucketPolicyInput{
+		Bucket: aws.String(c.PolicyValidationBucket),
+	})
+	if err != nil {
+		diags.AddWarning("could not clean up policy validation", fmt.Sprintf("could not delete the policy of the validation bucket '%s': %s", c.PolicyValidationBucket, err.Error()))
+	}
+	return diags
+}
+
+// validateAssumeRolePolicy sets the policy as trust policy of the canary role
+// set with policy_validation_role and restores its trust policy afterwards, so
+// parse errors of rgw are reported at plan time
+func (c *RgwClient) validateAssumeRolePolicy(ctx context.Context, attribute path.Path, policy string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if c.PolicyValidationRole == "" || c.ReadOnly {
+		return diags
+	}
+
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(policy)))
+	tags, err := c.listRoleTags(ctx, c.PolicyValidationRole)
+	if err == nil && tags[policyValidatedTag] == hash {
+		return diags
+	}
+
+	canary, err := c.getRole(ctx, c.PolicyValidationRole)
+	if err != nil {
+		diags.AddWarning("could not validate policy", fmt.Sprintf("could not get the validation role '%s': %s", c.PolicyValidationRole, err.Error()))
+		return diags
+	}
+
+	_, err = c.iamCall(ctx, "UpdateAssumeRolePolicy", url.Values{
+		"RoleName":       []string{c.PolicyValidationRole},
+		"PolicyDocument": []string{policy},
+	})
+	if err != nil {
+		var ae adminError
+		if errors.As(err, &ae) && isPolicyParseError(ae.Code, ae.Message) {
+			diags.AddAttributeError(attribute, "invalid policy", fmt.Sprintf("RGW rejected the policy on the validation role '%s': %s", c.PolicyValidationRole, err.Error()))
+			return diags
+		}
+		diags.AddWarning("could not validate policy", fmt.Sprintf("could not update the trust policy of the validation role '%s': %s", c.PolicyValidationRole, err.Error()))
+		return diags
+	}
+
+	// never leave an unreviewed trust policy on the canary
+	_, err = c.iamCall(ctx, "UpdateAssumeRolePolicy", url.Values{
+		"RoleName":       []string{c.PolicyValidationRole},
+		"PolicyDocument": []string{canary.AssumeRolePolicyDocument},
+	})
+	if err != nil {
+		diags.AddWarning("could not clean up policy validation", fmt.Sprintf("could not restore the trust policy of the validation role '%s', restore it manually: %s", c.PolicyValidationRole, err.Error()))
+		return diags
+	}
+
+	// failing to mark the policy only validates it again
+	_, err = c.iamCall(ctx, "TagRole", url.Values{
+		"RoleName":            []string{c.PolicyValidationRole},
+		"Tags.member.1.Key":   []string{policyValidatedTag},
+		"Tags.member.1.Value": []string{hash},
+	})
+	if err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("could not tag the validation role '%s': %s", c.PolicyValidationRole, err.Error()))
+	}
+
+	return diags
+}
+
+// listRoleTags lists the tags of a role
+func (c *RgwClient) listRoleTags(ctx context.Context, name string) (map[string]string, error) {
+	body, err := c.iamCall(ctx, "ListRoleTags", url.Values{"RoleName": []string{name}})
+	if err != nil {
+		return nil, err
+	}
+
+	type tag struct {
+		Key   string
+		Value string
+	}
+	var tags []tag
+	if err := xml.Unmarshal(body, &struct {
+		Tags *[]tag `xml:"ListRoleTagsResult>Tags>member"`
+	}{&tags}); err != nil {
+		return nil, fmt.Errorf("could not decode role tags: %w", err)
+	}
+
+	result := map[string]string{}
+	for _, t := range tags {
+		result[t.Key] = t.Value
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+func TestValidateAssumeRolePolicy(t *testing.T) {
+	original := `{"Version":"2012-10-17","Statement":[]}`
+	trustPolicy := original
+	tags := map[string]string{}
+	var actions []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		actions = append(actions, form.Get("Action"))
+		if form.Get("RoleName") != "canary" {
+			t.Errorf("unexpected request %v", form)
+		}
+		switch form.Get("Action") {
+		case "ListRoleTags":
+			_, _ = w.Write([]byte(`<ListRoleTagsResponse><ListRoleTagsResult><Tags>`))
+			for k, v := range tags {
+				_, _ = w.Write([]byte(`<member><Key>` + k + `</Key><Value>` + v + `</Value></member>`))
+			}
+			_, _ = w.Write([]byte(`</Tags></ListRoleTagsResult></ListRoleTagsResponse>`))
+		case "GetRole":
+			_, _ = w.Write([]byte(`<GetRoleResponse><GetRoleResult><Role><RoleName>canary</RoleName>` +
+				`<AssumeRolePolicyDocument>` + url.QueryEscape(trustPolicy) + `</AssumeRolePolicyDocument></Role></GetRoleResult></GetRoleResponse>`))
+		case "UpdateAssumeRolePolicy":
+			switch form.Get("PolicyDocument") {
+			case "{}":
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`<ErrorResponse><Error><Code>MalformedPolicyDocument</Code></Error></ErrorResponse>`))
+				return
+			case "too-long":
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`<ErrorResponse><Error><Code>InvalidArgument</Code><Message>invalid role name</Message></Error></ErrorResponse>`))
+				return
+			}
+			trustPolicy = form.Get("PolicyDocument")
+		case "TagRole":
+			tags[form.Get("Tags.member.1.Key")] = form.Get("Tags.member.1.Value")
+		default:
+			t.Errorf("unexpected action %s", form.Get("Action"))
+		}
+	})
+	attribute := path.Root("assume_role_policy")
+	policy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["arn:aws:iam:::user/ci"]},"Action":["sts:AssumeRole"]}]}`
+
+	// nothing is validated without canary role
+	if diags := client.validateAssumeRolePolicy(context.Background(), attribute, "{}"); diags.HasError() || actions != nil {
+		t.Errorf("expected no validation, got %v", diags)
+	}
+
+	client.PolicyValidationRole = "canary"
+	if diags := client.validateAssumeRolePolicy(context.Background(), attribute, policy); diags.HasError() || diags.WarningsCount() > 0 {
+		t.Errorf("expected valid policy, got %v", diags)
+	}
+	// the trust policy of the canary is restored
+	if trustPolicy != original {
+		t.Errorf("expected restored trust policy, got %s", trustPolicy)
+	}
+
+	// the validated policy isn't validated again at apply
+	actions = nil
+	if diags := client.validateAssumeRolePolicy(context.Background(), attribute, policy); diags.HasError() || len(actions) != 1 {
+		t.Errorf("expected no second validation, got %v and %v", diags, actions)
+	}
+
+	if diags := client.validateAssumeRolePolicy(context.Background(), attribute, "{}"); !diags.HasError() {
+		t.Error("expected invalid policy")
+	}
+
+	// InvalidArgument not about the policy is no parse error
+	if diags := client.validateAssumeRolePolicy(context.Background(), attribute, "too-long"); diags.HasError() || diags.WarningsCount() != 1 {
+		t.Errorf("expected warning only, got %v", diags)
+	}
+
+	// read only mode doesn't touch the canary
+	client.ReadOnly = true
+	actions = nil
+	if diags := client.validateAssumeRolePolicy(context.Background(), attribute, "{}"); diags.HasError() || actions != nil {
+		t.Errorf("expected no validation in read only mode, got %v", diags)
+	}
+}
+
+func TestIsPolicyParseError(t *testing.T) {
+	if !isPolicyParseError("MalformedPolicy", "") || !isPolicyParseError("InvalidArgument", "Policy has invalid resource") {
+		t.Error("expected parse errors")
+	}
+	if isPolicyParseError("InvalidArgument", "invalid bucket name") || isPolicyParseError("AccessDenied", "policy") {
+		t.Error("expected no parse errors")
+	}
+}
@@ -0,0 +1,251 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// rgwConditionKeys are the condition keys evaluated by rgw bucket policies
+var rgwConditionKeys = []string{
+	"aws:currenttime",
+	"aws:epochtime",
+	"aws:principaltype",
+	"aws:referer",
+	"aws:securetransport",
+	"aws:sourceip",
+	"aws:useragent",
+	"aws:username",
+	"s3:delimiter",
+	"s3:locationconstraint",
+	"s3:max-keys",
+	"s3:prefix",
+	"s3:requestobjecttagkeys",
+	"s3:versionid",
+	"s3:x-amz-acl",
+	"s3:x-amz-copy-source",
+	"s3:x-amz-grant-full-control",
+	"s3:x-amz-grant-read",
+	"s3:x-amz-grant-read-acp",
+	"s3:x-amz-grant-write",
+	"s3:x-amz-grant-write-acp",
+	"s3:x-amz-metadata-directive",
+	"s3:x-amz-server-side-encryption",
+	"s3:x-amz-server-side-encryption-aws-kms-key-id",
+	"s3:x-amz-storage-class",
+}
+
+// rgwConditionKeyPrefixes are condition keys with a user defined suffix
+var rgwConditionKeyPrefixes = []string{
+	"s3:existingobjecttag/",
+	"s3:requestobjecttag/",
+}
+
+type policyDocument struct {
+	Statement policyStatements `json:"Statement"`
+}
+
+// policyStatements accepts both a single statement and a list of statements
+type policyStatements []policyStatement
+
+func (s *policyStatements) UnmarshalJSON(b []byte) error {
+	var list []policyStatement
+	if err := json.Unmarshal(b, &list); err == nil {
+		*s = list
+		return nil
+	}
+
+	var single policyStatement
+	if err := json.Unmarshal(b, &single); err != nil {
+		return err
+	}
+	*s = policyStatements{single}
+	return nil
+}
+
+type policyStatement struct {
+	Sid          string                                `json:"Sid"`
+	Effect       string                                `json:"Effect"`
+	Principal    json.RawMessage                       `json:"Principal"`
+	NotPrincipal json.RawMessage                       `json:"NotPrincipal"`
+	Action       json.RawMessage                       `json:"Action"`
+	NotAction    json.RawMessage                       `json:"NotAction"`
+	Resource     json.RawMessage                       `json:"Resource"`
+	Condition    map[string]map[string]json.RawMessage `json:"Condition"`
+}
+
+// policyConditionValidator warns about condition keys ignored by rgw and
+// checks the values of aws:SourceIp conditions
+type policyConditionValidator struct{}
+
+func (v policyConditionValidator) Description(ctx context.Context) string {
+	return "Condition keys must be supported by RGW"
+}
+
+func (v policyConditionValidator) MarkdownDescription(ctx context.Context) string {
+	return "Condition keys must be supported by RGW"
+}
+
+func (v policyConditionValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	var doc policyDocument
+	if err := json.Unmarshal([]byte(req.ConfigValue.ValueString()), &doc); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid policy document", err.Error())
+		return
+	}
+
+	for i, stmt := range doc.Statement {
+		name := stmt.Sid
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		}
+
+		// sort operators and keys to get stable diagnostics
+		operators := make([]string, 0, len(stmt.Condition))
+		for op := range stmt.Condition {
+			operators = append(operators, op)
+		}
+		sort.Strings(operators)
+
+		for _, op := range operators {
+			keys := make([]string, 0, len(stmt.Condition[op]))
+			for key := range stmt.Condition[op] {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				value := stmt.Condition[op][key]
+				if !isRgwConditionKey(key) {
+					resp.Diagnostics.AddAttributeWarning(req.Path, "condition key ignored by rgw",
+						fmt.Sprintf("Statement %s uses condition key '%s' which is not evaluated by RGW. The condition will never restrict access as intended.", name, key))
+					continue
+				}
+
+				if strings.EqualFold(key, "aws:SourceIp") {
+					for _, ip := range conditionValues(value) {
+						if _, _, err := net.ParseCIDR(ip); err != nil && net.ParseIP(ip) == nil {
+							resp.Diagnostics.AddAttributeError(req.Path, "invalid aws:SourceIp condition",
+								fmt.Sprintf("Statement %s: '%s' is neither an ip address nor a cidr", name, ip))
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// checkPolicyResources warns about resource ARNs of a policy that name the
+// bucket without its tenant or with another tenant. rgw expects the tenant in
+// the account field, e.g. arn:aws:s3::tenant:bucket/*, other ARNs never match.
+func checkPolicyResources(bucket string, policy string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var doc policyDocument
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		// reported by the policy validator
+		return diags
+	}
+
+	tenant, _ := tenantOfBucket(bucket)
+	name := bucket
+	if i := strings.IndexAny(bucket, ":/"); i >= 0 {
+		name = bucket[i+1:]
+	}
+	expected := fmt.Sprintf("arn:aws:s3::%s:%s", tenant, name)
+
+	for i, stmt := range doc.Statement {
+		stmtName := stmt.Sid
+		if stmtName == "" {
+			stmtName = fmt.Sprintf("#%d", i)
+		}
+
+		for _, arn := range conditionValues(stmt.Resource) {
+			// arn:partition:service:region:account:resource
+			parts := strings.SplitN(arn, ":", 6)
+			if len(parts) != 6 || parts[0] != "arn" || parts[2] != "s3" {
+				continue
+			}
+			account := parts[4]
+			resourceBucket := strings.SplitN(parts[5], "/", 2)[0]
+
+			// tenant put into the resource instead of the account field
+			if i := strings.Index(resourceBucket, ":"); i >= 0 {
+				if resourceBucket[i+1:] == name {
+					diags.AddAttributeWarning(path.Root("policy"), "policy resource never matches",
+						fmt.Sprintf("Statement %s: resource '%s' puts the tenant into the bucket name. RGW expects the tenant in the account field of the ARN, e.g. '%s'.", stmtName, arn, expected))
+				}
+				continue
+			}
+
+			if resourceBucket == name && account != tenant {
+				diags.AddAttributeWarning(path.Root("policy"), "policy resource never matches",
+					fmt.Sprintf("Statement %s: resource '%s' does not match the tenant '%s' of bucket '%s'. Use the tenant qualified ARN '%s'.", stmtName, arn, tenant, bucket, expected))
+			}
+		}
+	}
+
+	return diags
+}
+
+// isRgwConditionKey checks whether rgw evaluates the condition key
+func isRgwConditionKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range rgwConditionKeys {
+		if key == k {
+			return true
+		}
+	}
+	for _, p := range rgwConditionKeyPrefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionValues returns the values of a condition, which can be a string or a list of strings
+func conditionValues(raw json.RawMessage) []string {
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}
+	}
+
+	return nil
+}
+
+// jsonValidator checks that a string is a valid json document
+type jsonValidator struct{}
+
+func (v jsonValidator) Description(ctx context.Context) string {
+	return "must be a valid JSON document"
+}
+
+func (v jsonValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v jsonValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if !json.Valid([]byte(req.ConfigValue.ValueString())) {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid JSON", "The value is not a valid JSON document, use jsonencode().")
+	}
+}
@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = &PresignedUrlDataSource{}
+
+func NewPresignedUrlDataSource() datasource.DataSource {
+	return &PresignedUrlDataSource{}
+}
+
+type PresignedUrlDataSource struct {
+	client *RgwClient
+}
+
+type PresignedUrlDataSourceModel struct {
+	Id        types.String `tfsdk:"id"`
+	Bucket    types.String `tfsdk:"bucket"`
+	Key       types.String `tfsdk:"key"`
+	Method    types.String `tfsdk:"method"`
+	ExpiresIn types.Int64  `tfsdk:"expires_in"`
+	AccessKey types.String `tfsdk:"access_key"`
+	SecretKey types.String `tfsdk:"secret_key"`
+	Url       types.String `tfsdk:"url"`
+}
+
+func (d *PresignedUrlDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_presigned_url"
+}
+
+func (d *PresignedUrlDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Presigned URL granting temporary access to an object. Note that the URL is regenerated on every refresh.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Bucket Name",
+				Required:            true,
+			},
+			"key": schema.StringAttribute{
+				MarkdownDescription: "Object Key",
+				Required:            true,
+			},
+			"method": schema.StringAttribute{
+				MarkdownDescription: "HTTP method the URL is valid for, `GET` or `PUT`. Defaults to `GET`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("GET", "PUT"),
+				},
+			},
+			"expires_in": schema.Int64Attribute{
+				MarkdownDescription: "Validity of the URL in seconds. Defaults to `3600`.",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.Between(1, 604800),
+				},
+			},
+			"access_key": schema.StringAttribute{
+				MarkdownDescription: "Access key used to sign the URL. Defaults to the provider credentials.",
+				Optional:            true,
+			},
+			"secret_key": schema.StringAttribute{
+				MarkdownDescription: "Secret key used to sign the URL. Required if `access_key` is set.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"url": schema.StringAttribute{
+				MarkdownDescription: "The presigned URL",
+				Computed:            true,
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (d *PresignedUrlDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "data.rgw_presigned_url")...)
+}
+
+func (d *PresignedUrlDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *PresignedUrlDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// sign with user credentials if given
+	s3client := d.client.S3
+	if !data.AccessKey.IsNull() {
+		if data.SecretKey.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("secret_key"), "missing secret key", "secret_key is required if access_key is set")
+			return
+		}
+		s3client = d.client.newS3Client(data.AccessKey.ValueString(), data.SecretKey.ValueString())
+	}
+
+	if data.Method.IsNull() {
+		data.Method = types.StringValue("GET")
+	}
+	if data.ExpiresIn.IsNull() {
+		data.ExpiresIn = types.Int64Value(3600)
+	}
+
+	presigner := s3.NewPresignClient(s3client, s3.WithPresignExpires(time.Duration(data.ExpiresIn.ValueInt64())*time.Second))
+
+	var url string
+	switch data.Method.ValueString() {
+	case "PUT":
+		presigned, err := presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(data.Bucket.ValueString()),
+			Key:    aws.String(data.Key.ValueString()),
+		})
+		if err != nil {
+			resp.Diagnostics.AddError("could not presign url", err.Error())
+			return
+		}
+		url = presigned.URL
+	default:
+		presigned, err := presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(data.Bucket.ValueString()),
+			Key:    aws.String(data.Key.ValueString()),
+		})
+		if err != nil {
+			resp.Diagnostics.AddError("could not presign url", err.Error())
+			return
+		}
+		url = presigned.URL
+	}
+
+	data.Id = types.StringValue(fmt.Sprintf("%s/%s", data.Bucket.ValueString(), data.Key.ValueString()))
+	data.Url = types.StringValue(url)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -2,15 +2,28 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -28,14 +41,112 @@ type RgwProvider struct {
 
 // RgwProviderModel describes the provider data model.
 type RgwProviderModel struct {
-	Endpoint  types.String `tfsdk:"endpoint"`
-	AccessKey types.String `tfsdk:"access_key"`
-	SecretKey types.String `tfsdk:"secret_key"`
+	Endpoint       types.String `tfsdk:"endpoint"`
+	AccessKey      types.String `tfsdk:"access_key"`
+	SecretKey      types.String `tfsdk:"secret_key"`
+	CephVersion    types.String `tfsdk:"ceph_version"`
+	S3Endpoint     types.String `tfsdk:"s3_endpoint"`
+	ForcePathStyle types.Bool   `tfsdk:"force_path_style"`
+	ReadOnly       types.Bool   `tfsdk:"read_only"`
+	CapsCheck      types.String `tfsdk:"required_caps_check"`
+	AllowedTenants types.List   `tfsdk:"allowed_tenants"`
+	ProtectedUids  types.List   `tfsdk:"protected_uids"`
+	EmailPolicy    types.String `tfsdk:"user_email_policy"`
+	DriftWarnings  types.Bool   `tfsdk:"drift_warnings"`
+	ExtraCapTypes  types.List   `tfsdk:"extra_cap_types"`
+	UserPrefix     types.String `tfsdk:"user_prefix"`
+	BucketPrefix   types.String `tfsdk:"bucket_prefix"`
+	PrependPrefix  types.Bool   `tfsdk:"prepend_prefix"`
+	DefaultLabels  types.Map    `tfsdk:"default_labels"`
+	QuotaVerify    types.String `tfsdk:"quota_verify_timeout"`
+	MetricsOutput  types.String `tfsdk:"metrics_output"`
+	PolicyBucket   types.String `tfsdk:"policy_validation_bucket"`
+	PolicyRole     types.String `tfsdk:"policy_validation_role"`
+	MaxUserKeys    types.Int64  `tfsdk:"max_user_keys"`
 }
 
 type RgwClient struct {
-	Admin *admin.API
-	S3    *s3.Client
+	Admin          *admin.API
+	S3             *s3.Client
+	Version        *cephVersion
+	S3Endpoint     string
+	ForcePathStyle bool
+	ReadOnly       bool
+
+	// DriftWarnings reports attributes changed outside of Terraform on refresh
+	DriftWarnings bool
+
+	// AllowedTenants restricts resources to these tenants, nil if unrestricted
+	AllowedTenants []string
+
+	// ProtectedUids are users which must not be destroyed or have their keys modified
+	ProtectedUids []string
+
+	// ExtraCapTypes are accepted as cap types in addition to the known ones
+	ExtraCapTypes []string
+
+	// UserEmailPolicy must match the email of every rgw_user, nil if unrestricted
+	UserEmailPolicy *regexp.Regexp
+
+	// UserPrefix and BucketPrefix must start all user IDs and bucket names, empty if unrestricted
+	UserPrefix   string
+	BucketPrefix string
+
+	// PrependPrefix prepends the prefixes to the names of rgw_user and rgw_bucket
+	PrependPrefix bool
+
+	// DefaultLabels are stamped on created buckets, topics and roles
+	DefaultLabels map[string]string
+
+	// QuotaVerifyTimeout is how long quotas are read back after setting them, 0 to not verify
+	QuotaVerifyTimeout time.Duration
+
+	// Metrics records the api requests if metrics_output is set, nil otherwise
+	Metrics *apiMetrics
+
+	// PolicyValidationBucket and PolicyValidationRole are canaries policies
+	// are put on at plan time to validate them, empty to not validate
+	PolicyValidationBucket string
+	PolicyValidationRole   string
+
+	// MaxUserKeys is the number of s3 keys a user may have, 0 if unrestricted
+	MaxUserKeys int64
+
+	// RequiredCapsCheck is "strict" to check the caps of the provider
+	// credentials before resources and data sources are used
+	RequiredCapsCheck string
+	identity          *adminIdentity
+	identityLock      sync.Mutex
+}
+
+// newS3Client creates a s3 client for the configured s3 endpoint using the given credentials
+func (c *RgwClient) newS3Client(accessKey string, secretKey string) *s3.Client {
+	var httpClient s3.HTTPClient
+	if c.Metrics != nil {
+		httpClient = &metricsClient{next: awshttp.NewBuildableClient(), metrics: c.Metrics}
+	}
+
+	return s3.New(s3.Options{
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{
+				AccessKeyID:     accessKey,
+				SecretAccessKey: secretKey,
+			}, nil
+		}),
+		EndpointResolver: s3.EndpointResolverFromURL(c.S3Endpoint),
+		UsePathStyle:     c.ForcePathStyle,
+		HTTPClient:       httpClient,
+	})
+}
+
+// checkWritable returns an error diagnostic if the provider is configured read only
+func (c *RgwClient) checkWritable(operation string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if c.ReadOnly {
+		diags.AddError("provider is read only",
+			fmt.Sprintf("Cannot %s, the provider is configured with read_only = true. Only reads and data sources are allowed.", operation))
+	}
+	return diags
 }
 
 func (p *RgwProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -59,6 +170,96 @@ func (p *RgwProvider) Schema(ctx context.Context, req provider.SchemaRequest, re
 				Optional:            true,
 				Sensitive:           true,
 			},
+			"s3_endpoint": schema.StringAttribute{
+				MarkdownDescription: "Endpoint URL used for S3 api calls of bucket resources, e.g. a public VIP while `endpoint` points to an internal one. Defaults to `endpoint`. Can be set via env 'TF_PROVIDER_RGW_S3_ENDPOINT'",
+				Optional:            true,
+			},
+			"drift_warnings": schema.BoolAttribute{
+				MarkdownDescription: "Emit a warning on refresh listing every attribute changed outside of Terraform with its server-side value. Sensitive values are redacted. Useful for compliance review of CI plan logs. Defaults to `false`. Can be set via env 'TF_PROVIDER_RGW_DRIFT_WARNINGS'",
+				Optional:            true,
+			},
+			"extra_cap_types": schema.ListAttribute{
+				MarkdownDescription: "Additional cap types accepted in `caps` of `rgw_user`, for cap types of Ceph releases newer than the provider. Can be set as comma separated list via env 'TF_PROVIDER_RGW_EXTRA_CAP_TYPES'",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"force_path_style": schema.BoolAttribute{
+				MarkdownDescription: "Use path-style addressing (`https://endpoint/bucket`) for S3 api calls. Set to `false` to use virtual-hosted-style addressing, which requires wildcard DNS. Defaults to `true`. Can be set via env 'TF_PROVIDER_RGW_FORCE_PATH_STYLE'",
+				Optional:            true,
+			},
+			"ceph_version": schema.StringAttribute{
+				MarkdownDescription: "Ceph version of the RGW, e.g. `18.2.1` or `reef`. RGW does not expose its version, so features requiring newer releases are only gated if this is set. Can be set via env 'TF_PROVIDER_RGW_CEPH_VERSION'",
+				Optional:            true,
+			},
+			"required_caps_check": schema.StringAttribute{
+				MarkdownDescription: "Set to `strict` to check that the user of the provider credentials has the admin caps required by the resources and data sources in the configuration, and to report exactly which caps are missing. This supports least-privilege admin users. Defaults to `none`. Can be set via env 'TF_PROVIDER_RGW_REQUIRED_CAPS_CHECK'",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("none", "strict"),
+				},
+			},
+			"allowed_tenants": schema.ListAttribute{
+				MarkdownDescription: "Restrict resources to these tenants. Plans touching users or buckets of other tenants fail. Use `\"\"` for the default tenant. Buckets without explicit tenant belong to the tenant of the provider credentials. Can be set as comma separated list via env 'TF_PROVIDER_RGW_ALLOWED_TENANTS'",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"protected_uids": schema.ListAttribute{
+				MarkdownDescription: "User IDs (`tenant$username` or `username`) which must not be destroyed, replaced or have their keys modified, e.g. multisite system users or the dashboard user. Can be set as comma separated list via env 'TF_PROVIDER_RGW_PROTECTED_UIDS'",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"user_email_policy": schema.StringAttribute{
+				MarkdownDescription: "Regular expression every `email` of `rgw_user` has to match, e.g. `@example\\.com$`. Checked at plan time, empty emails are not checked. Can be set via env 'TF_PROVIDER_RGW_USER_EMAIL_POLICY'",
+				Optional:            true,
+			},
+			"user_prefix": schema.StringAttribute{
+				MarkdownDescription: "Prefix every user ID (after the tenant) has to start with, so workspaces sharing a cluster cannot collide. Checked at plan time on all resources referencing users. Can be set via env 'TF_PROVIDER_RGW_USER_PREFIX'",
+				Optional:            true,
+			},
+			"bucket_prefix": schema.StringAttribute{
+				MarkdownDescription: "Prefix every bucket name (after the tenant) has to start with, so workspaces sharing a cluster cannot collide. Checked at plan time on all resources referencing buckets. Can be set via env 'TF_PROVIDER_RGW_BUCKET_PREFIX'",
+				Optional:            true,
+			},
+			"prepend_prefix": schema.BoolAttribute{
+				MarkdownDescription: "Prepend `user_prefix` and `bucket_prefix` to the `username` of `rgw_user` and the `name` of `rgw_bucket` instead of rejecting names without them. The `id` of both resources holds the full name and has to be referenced by other resources. Defaults to `false`. Can be set via env 'TF_PROVIDER_RGW_PREPEND_PREFIX'",
+				Optional:            true,
+			},
+			"default_labels": schema.MapAttribute{
+				MarkdownDescription: "Labels stamped on every created bucket (as tags), topic (as JSON `OpaqueData` if `opaque_data` is not set) and role (as tags), e.g. the workspace and owner for cluster-side auditing of Terraform managed objects. Labels are not shown as drift of `rgw_bucket_tagging`, whose tags take precedence.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(stringvalidator.LengthBetween(1, 128)),
+					mapvalidator.ValueStringsAre(stringvalidator.LengthAtMost(256)),
+				},
+			},
+			"quota_verify_timeout": schema.StringAttribute{
+				MarkdownDescription: "Read quotas back after setting them until they are visible or this duration passes, e.g. `30s`, and warn if they are not. Useful when quota cache propagation delays matter, e.g. during incident response. Disabled by default. Can be set via env 'TF_PROVIDER_RGW_QUOTA_VERIFY_TIMEOUT'",
+				Optional:            true,
+			},
+			"metrics_output": schema.StringAttribute{
+				MarkdownDescription: "Record the requests sent to the RGW apis and write them in the Prometheus text format when Terraform shuts the provider down, i.e. at the end of each plan or apply: request counts by api, operation and status code, retries and durations. Set to a file path, e.g. for the textfile collector of the node exporter, or to `log` to write them to the provider log. Disabled by default. Can be set via env 'TF_PROVIDER_RGW_METRICS_OUTPUT'",
+				Optional:            true,
+			},
+			"policy_validation_bucket": schema.StringAttribute{
+				MarkdownDescription: "Existing bucket of the provider credentials used as canary to validate bucket policies at plan time: changed policies of `rgw_bucket_policy` are put on it and removed again, so policies rejected by the RGW parser fail the plan instead of the apply. Skipped in read only mode. Disabled by default. Can be set via env 'TF_PROVIDER_RGW_POLICY_VALIDATION_BUCKET'",
+				Optional:            true,
+			},
+			"policy_validation_role": schema.StringAttribute{
+				MarkdownDescription: "Existing role used as canary to validate trust policies at plan time: changed `assume_role_policy` of `rgw_role` are set as its trust policy and its own trust policy is restored afterwards, so policies rejected by the RGW parser fail the plan instead of the apply. The role must have no permissions attached, as it briefly trusts unreviewed policies. Valid policies are marked with a tag on the role, so they are not validated again at apply. Skipped in read only mode. Disabled by default. Can be set via env 'TF_PROVIDER_RGW_POLICY_VALIDATION_ROLE'",
+				Optional:            true,
+			},
+			"max_user_keys": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of S3 keys per user, e.g. the limit RGW enforces for the users of accounts. Planning a new `rgw_user_key` for a user which has this many keys already fails with the keys listed, instead of the apply failing on the api. Keys replaced in the same plan don't count. Unrestricted by default. Can be set via env 'TF_PROVIDER_RGW_MAX_USER_KEYS'",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"read_only": schema.BoolAttribute{
+				MarkdownDescription: "Refuse to create, update or delete any resource, while reads and data sources keep working. Useful for audit workspaces pointed at production clusters. Defaults to `false`. Can be set via env 'TF_PROVIDER_RGW_READ_ONLY'",
+				Optional:            true,
+			},
 		},
 	}
 }
@@ -83,6 +284,198 @@ func (p *RgwProvider) Configure(ctx context.Context, req provider.ConfigureReque
 		data.SecretKey = types.StringValue(os.Getenv("TF_PROVIDER_RGW_SECRET_KEY"))
 	}
 
+	if data.S3Endpoint.IsNull() {
+		data.S3Endpoint = types.StringValue(os.Getenv("TF_PROVIDER_RGW_S3_ENDPOINT"))
+	}
+	if data.S3Endpoint.ValueString() == "" {
+		data.S3Endpoint = data.Endpoint
+	}
+
+	if data.ForcePathStyle.IsNull() {
+		data.ForcePathStyle = types.BoolValue(true)
+		if env := os.Getenv("TF_PROVIDER_RGW_FORCE_PATH_STYLE"); env != "" {
+			forcePathStyle, err := strconv.ParseBool(env)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("force_path_style"), "invalid value of TF_PROVIDER_RGW_FORCE_PATH_STYLE", err.Error())
+				return
+			}
+			data.ForcePathStyle = types.BoolValue(forcePathStyle)
+		}
+	}
+
+	if data.ReadOnly.IsNull() {
+		data.ReadOnly = types.BoolValue(false)
+		if env := os.Getenv("TF_PROVIDER_RGW_READ_ONLY"); env != "" {
+			readOnly, err := strconv.ParseBool(env)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("read_only"), "invalid value of TF_PROVIDER_RGW_READ_ONLY", err.Error())
+				return
+			}
+			data.ReadOnly = types.BoolValue(readOnly)
+		}
+	}
+
+	if data.DriftWarnings.IsNull() {
+		data.DriftWarnings = types.BoolValue(false)
+		if env := os.Getenv("TF_PROVIDER_RGW_DRIFT_WARNINGS"); env != "" {
+			driftWarnings, err := strconv.ParseBool(env)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("drift_warnings"), "invalid value of TF_PROVIDER_RGW_DRIFT_WARNINGS", err.Error())
+				return
+			}
+			data.DriftWarnings = types.BoolValue(driftWarnings)
+		}
+	}
+
+	var allowedTenants []string
+	if !data.AllowedTenants.IsNull() {
+		resp.Diagnostics.Append(data.AllowedTenants.ElementsAs(ctx, &allowedTenants, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	} else if env, ok := os.LookupEnv("TF_PROVIDER_RGW_ALLOWED_TENANTS"); ok {
+		allowedTenants = strings.Split(env, ",")
+		for i := range allowedTenants {
+			allowedTenants[i] = strings.TrimSpace(allowedTenants[i])
+		}
+	}
+
+	var protectedUids []string
+	if !data.ProtectedUids.IsNull() {
+		resp.Diagnostics.Append(data.ProtectedUids.ElementsAs(ctx, &protectedUids, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	} else if env := os.Getenv("TF_PROVIDER_RGW_PROTECTED_UIDS"); env != "" {
+		protectedUids = strings.Split(env, ",")
+		for i := range protectedUids {
+			protectedUids[i] = strings.TrimSpace(protectedUids[i])
+		}
+	}
+
+	var defaultLabels map[string]string
+	if !data.DefaultLabels.IsNull() {
+		resp.Diagnostics.Append(data.DefaultLabels.ElementsAs(ctx, &defaultLabels, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	var extraCapTypes []string
+	if !data.ExtraCapTypes.IsNull() {
+		resp.Diagnostics.Append(data.ExtraCapTypes.ElementsAs(ctx, &extraCapTypes, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	} else if env := os.Getenv("TF_PROVIDER_RGW_EXTRA_CAP_TYPES"); env != "" {
+		extraCapTypes = strings.Split(env, ",")
+		for i := range extraCapTypes {
+			extraCapTypes[i] = strings.TrimSpace(extraCapTypes[i])
+		}
+	}
+
+	if data.EmailPolicy.IsNull() {
+		data.EmailPolicy = types.StringValue(os.Getenv("TF_PROVIDER_RGW_USER_EMAIL_POLICY"))
+	}
+
+	var emailPolicy *regexp.Regexp
+	if data.EmailPolicy.ValueString() != "" {
+		var err error
+		emailPolicy, err = regexp.Compile(data.EmailPolicy.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("user_email_policy"), "invalid user email policy", err.Error())
+			return
+		}
+	}
+
+	if data.UserPrefix.IsNull() {
+		data.UserPrefix = types.StringValue(os.Getenv("TF_PROVIDER_RGW_USER_PREFIX"))
+	}
+
+	if data.BucketPrefix.IsNull() {
+		data.BucketPrefix = types.StringValue(os.Getenv("TF_PROVIDER_RGW_BUCKET_PREFIX"))
+	}
+
+	if data.PrependPrefix.IsNull() {
+		data.PrependPrefix = types.BoolValue(false)
+		if env := os.Getenv("TF_PROVIDER_RGW_PREPEND_PREFIX"); env != "" {
+			prependPrefix, err := strconv.ParseBool(env)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("prepend_prefix"), "invalid value of TF_PROVIDER_RGW_PREPEND_PREFIX", err.Error())
+				return
+			}
+			data.PrependPrefix = types.BoolValue(prependPrefix)
+		}
+	}
+
+	if data.QuotaVerify.IsNull() {
+		data.QuotaVerify = types.StringValue(os.Getenv("TF_PROVIDER_RGW_QUOTA_VERIFY_TIMEOUT"))
+	}
+
+	var quotaVerifyTimeout time.Duration
+	if data.QuotaVerify.ValueString() != "" {
+		var err error
+		quotaVerifyTimeout, err = time.ParseDuration(data.QuotaVerify.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("quota_verify_timeout"), "invalid quota verify timeout", err.Error())
+			return
+		}
+	}
+
+	if data.MetricsOutput.IsNull() {
+		data.MetricsOutput = types.StringValue(os.Getenv("TF_PROVIDER_RGW_METRICS_OUTPUT"))
+	}
+
+	var metrics *apiMetrics
+	if data.MetricsOutput.ValueString() != "" {
+		metrics = metricsFor(data.MetricsOutput.ValueString())
+	}
+
+	if data.PolicyBucket.IsNull() {
+		data.PolicyBucket = types.StringValue(os.Getenv("TF_PROVIDER_RGW_POLICY_VALIDATION_BUCKET"))
+	}
+	if data.PolicyRole.IsNull() {
+		data.PolicyRole = types.StringValue(os.Getenv("TF_PROVIDER_RGW_POLICY_VALIDATION_ROLE"))
+	}
+
+	if data.MaxUserKeys.IsNull() {
+		data.MaxUserKeys = types.Int64Value(0)
+		if env := os.Getenv("TF_PROVIDER_RGW_MAX_USER_KEYS"); env != "" {
+			maxUserKeys, err := strconv.ParseInt(env, 10, 64)
+			if err != nil || maxUserKeys < 1 {
+				resp.Diagnostics.AddAttributeError(path.Root("max_user_keys"), "invalid value of TF_PROVIDER_RGW_MAX_USER_KEYS", "expected a positive number")
+				return
+			}
+			data.MaxUserKeys = types.Int64Value(maxUserKeys)
+		}
+	}
+
+	if data.CapsCheck.IsNull() {
+		data.CapsCheck = types.StringValue(os.Getenv("TF_PROVIDER_RGW_REQUIRED_CAPS_CHECK"))
+	}
+	switch data.CapsCheck.ValueString() {
+	case "", "none", "strict":
+	default:
+		resp.Diagnostics.AddAttributeError(path.Root("required_caps_check"), "invalid value of TF_PROVIDER_RGW_REQUIRED_CAPS_CHECK", "expected 'none' or 'strict'")
+		return
+	}
+
+	if data.CephVersion.IsNull() {
+		data.CephVersion = types.StringValue(os.Getenv("TF_PROVIDER_RGW_CEPH_VERSION"))
+	}
+
+	// Parse ceph version used for feature gating
+	var version *cephVersion
+	if data.CephVersion.ValueString() != "" {
+		v, err := parseCephVersion(data.CephVersion.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("ceph_version"), "invalid ceph version", err.Error())
+			return
+		}
+		version = &v
+		tflog.Debug(ctx, fmt.Sprintf("Gating features for Ceph %s", v))
+	}
+
 	// Create Ceph RGW Admin Client
 	tflog.Debug(ctx, "Configuring Ceph RGW admin client")
 	admin, err := admin.New(data.Endpoint.ValueString(), data.AccessKey.ValueString(), data.SecretKey.ValueString(), nil)
@@ -91,23 +484,44 @@ func (p *RgwProvider) Configure(ctx context.Context, req provider.ConfigureReque
 		return
 	}
 
+	// record the requests sent to the admin, iam and sns apis
+	if metrics != nil {
+		admin.HTTPClient = &metricsClient{next: admin.HTTPClient, metrics: metrics}
+	}
+
+	// name the missing admin cap in AccessDenied errors
+	admin.HTTPClient = &capsHintClient{next: admin.HTTPClient}
+
 	// Create s3 client
 	tflog.Debug(ctx, "Configuring S3 client from AWS SDK")
-	s3client := s3.New(s3.Options{
-		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
-			return aws.Credentials{
-				AccessKeyID:     data.AccessKey.ValueString(),
-				SecretAccessKey: data.SecretKey.ValueString(),
-			}, nil
-		}),
-		EndpointResolver: s3.EndpointResolverFromURL(data.Endpoint.ValueString()),
-		UsePathStyle:     true,
-	})
-
 	client := &RgwClient{
-		Admin: admin,
-		S3:    s3client,
+		Admin:          admin,
+		Version:        version,
+		S3Endpoint:     data.S3Endpoint.ValueString(),
+		ForcePathStyle: data.ForcePathStyle.ValueBool(),
+		ReadOnly:       data.ReadOnly.ValueBool(),
+		DriftWarnings:  data.DriftWarnings.ValueBool(),
+		AllowedTenants: allowedTenants,
+		ProtectedUids:  protectedUids,
+		ExtraCapTypes:  extraCapTypes,
+
+		UserEmailPolicy: emailPolicy,
+		UserPrefix:      data.UserPrefix.ValueString(),
+		BucketPrefix:    data.BucketPrefix.ValueString(),
+		PrependPrefix:   data.PrependPrefix.ValueBool(),
+		DefaultLabels:   defaultLabels,
+
+		QuotaVerifyTimeout: quotaVerifyTimeout,
+		Metrics:            metrics,
+
+		PolicyValidationBucket: data.PolicyBucket.ValueString(),
+		PolicyValidationRole:   data.PolicyRole.ValueString(),
+
+		MaxUserKeys: data.MaxUserKeys.ValueInt64(),
+
+		RequiredCapsCheck: data.CapsCheck.ValueString(),
 	}
+	client.S3 = client.newS3Client(data.AccessKey.ValueString(), data.SecretKey.ValueString())
 
 	resp.DataSourceData = client
 	resp.ResourceData = client
@@ -118,11 +532,49 @@ func (p *RgwProvider) Resources(ctx context.Context) []func() resource.Resource
 		NewBucketResource,
 		NewUserResource,
 		NewBucketPolicyResource,
+		NewUserKeyResource,
+		NewSubuserResource,
+		NewUserDefaultBucketQuotaResource,
+		NewBucketQuotaResource,
+		NewBucketLifecycleConfigurationResource,
+		NewBucketVersioningResource,
+		NewBucketObjectLockConfigurationResource,
+		NewBucketCorsResource,
+		NewBucketWebsiteResource,
+		NewBucketTaggingResource,
+		NewBucketEncryptionResource,
+		NewTopicResource,
+		NewRoleResource,
+		NewRolePolicyAttachmentResource,
+		NewOidcProviderResource,
+		NewUserPolicyResource,
+		NewBucketRateLimitResource,
+		NewObjectCopyResource,
+		NewBucketLinkResource,
+		NewObjectResource,
+		NewAccountResource,
+		NewAccountUserResource,
 	}
 }
 
 func (p *RgwProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewUserDataSource,
+		NewUsageSummaryDataSource,
+		NewPresignedUrlDataSource,
+		NewQuotaDefaultsDataSource,
+		NewOidcProvidersDataSource,
+		NewExistsDataSource,
+		NewMetadataDataSource,
+		NewUserSubusersDataSource,
+		NewUsersDataSource,
+		NewUserQuotaUsageDataSource,
+		NewEffectivePermissionsDataSource,
+		NewBucketObjectsDataSource,
+		NewDisplayNameDataSource,
+		NewAccountMigrationDataSource,
+		NewCredentialsFileDataSource,
+	}
 }
 
 func New(version string) func() provider.Provider {
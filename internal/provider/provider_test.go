@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// configureProvider configures the provider with the given attributes, all
+// others are null
+func configureProvider(t *testing.T, attributes map[string]tftypes.Value) *RgwClient {
+	t.Helper()
+	ctx := context.Background()
+	p := New("test")()
+
+	schemaResp := &provider.SchemaResponse{}
+	p.Schema(ctx, provider.SchemaRequest{}, schemaResp)
+	objectType, ok := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	if !ok {
+		t.Fatalf("unexpected provider schema type %T", schemaResp.Schema.Type().TerraformType(ctx))
+	}
+
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attrType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attrType, nil)
+	}
+	for name, value := range attributes {
+		values[name] = value
+	}
+
+	resp := &provider.ConfigureResponse{}
+	p.Configure(ctx, provider.ConfigureRequest{
+		Config: tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(objectType, values),
+		},
+	}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	client, ok := resp.ResourceData.(*RgwClient)
+	if !ok {
+		t.Fatalf("unexpected resource data %T", resp.ResourceData)
+	}
+
+	return client
+}
+
+func TestConfigureExtraCapTypes(t *testing.T) {
+	client := configureProvider(t, map[string]tftypes.Value{
+		"endpoint":   tftypes.NewValue(tftypes.String, "http://localhost:7480"),
+		"access_key": tftypes.NewValue(tftypes.String, "access"),
+		"secret_key": tftypes.NewValue(tftypes.String, "secret"),
+		"extra_cap_types": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{
+			tftypes.NewValue(tftypes.String, "new-cap"),
+		}),
+	})
+
+	if err := client.validateCapType("new-cap"); err != nil {
+		t.Errorf("expected configured extra cap type to be accepted: %s", err)
+	}
+	if err := client.validateCapType("other-cap"); err == nil {
+		t.Error("expected unknown cap type to be rejected")
+	}
+}
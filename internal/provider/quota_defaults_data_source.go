@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = &QuotaDefaultsDataSource{}
+
+func NewQuotaDefaultsDataSource() datasource.DataSource {
+	return &QuotaDefaultsDataSource{}
+}
+
+type QuotaDefaultsDataSource struct {
+	client *RgwClient
+}
+
+type QuotaDefaultsDataSourceModel struct {
+	Id          types.String    `tfsdk:"id"`
+	UserQuota   *UserQuotaModel `tfsdk:"user_quota"`
+	BucketQuota *UserQuotaModel `tfsdk:"bucket_quota"`
+}
+
+func (d *QuotaDefaultsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_quota_defaults"
+}
+
+func (d *QuotaDefaultsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Default quotas applied by the realm to users without explicit quotas",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"user_quota": schema.SingleNestedAttribute{
+				MarkdownDescription: "Default user quota",
+				Computed:            true,
+				Attributes:          quotaDefaultsAttributes("user"),
+			},
+			"bucket_quota": schema.SingleNestedAttribute{
+				MarkdownDescription: "Default bucket quota",
+				Computed:            true,
+				Attributes:          quotaDefaultsAttributes("bucket"),
+			},
+		},
+	}
+}
+
+func quotaDefaultsAttributes(quotaType string) map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"enabled": schema.BoolAttribute{
+			MarkdownDescription: fmt.Sprintf("Whether the default %s quota is enabled", quotaType),
+			Computed:            true,
+		},
+		"max_size_kb": schema.Int64Attribute{
+			MarkdownDescription: "Maximum size in KB, -1 means unlimited",
+			Computed:            true,
+		},
+		"max_objects": schema.Int64Attribute{
+			MarkdownDescription: "Maximum number of objects, -1 means unlimited",
+			Computed:            true,
+		},
+	}
+}
+
+func (d *QuotaDefaultsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "data.rgw_quota_defaults")...)
+}
+
+func (d *QuotaDefaultsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *QuotaDefaultsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config, err := d.client.getPeriodConfig(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("could not get period config", err.Error())
+		return
+	}
+
+	data.Id = types.StringValue("quota_defaults")
+	data.UserQuota = quotaModelFromInfo(config.UserQuota)
+	data.BucketQuota = quotaModelFromInfo(config.BucketQuota)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// quotaModelFromInfo converts a quota as encoded by rgw to the model
+func quotaModelFromInfo(q quotaInfo) *UserQuotaModel {
+	return &UserQuotaModel{
+		Enabled:    types.BoolValue(q.Enabled),
+		MaxSizeKb:  types.Int64Value(q.sizeKb()),
+		MaxObjects: types.Int64Value(q.MaxObjects),
+	}
+}
@@ -0,0 +1,266 @@
+package provider
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &RolePolicyAttachmentResource{}
+var _ resource.ResourceWithModifyPlan = &RolePolicyAttachmentResource{}
+var _ resource.ResourceWithImportState = &RolePolicyAttachmentResource{}
+
+func NewRolePolicyAttachmentResource() resource.Resource {
+	return &RolePolicyAttachmentResource{}
+}
+
+type RolePolicyAttachmentResource struct {
+	client *RgwClient
+}
+
+type RolePolicyAttachmentResourceModel struct {
+	Id        types.String `tfsdk:"id"`
+	Role      types.String `tfsdk:"role"`
+	PolicyArn types.String `tfsdk:"policy_arn"`
+}
+
+func (r *RolePolicyAttachmentResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_role_policy_attachment"
+}
+
+func (r *RolePolicyAttachmentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Attachment of a managed policy to a role. Requires Ceph >= 19.2 (Squid).",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"role": schema.StringAttribute{
+				MarkdownDescription: "Role Name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, roleNameMaxLength),
+				},
+			},
+			"policy_arn": schema.StringAttribute{
+				MarkdownDescription: "ARN of the managed policy, e.g. `arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess`",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^arn:aws:iam::[^:]*:policy/.+$`), "must be the ARN of a managed policy"),
+				},
+			},
+		},
+	}
+}
+
+func (r *RolePolicyAttachmentResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_role_policy_attachment")...)
+}
+
+func (r *RolePolicyAttachmentResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "role", tenantOfCredentials)...)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.client.requireFeature(featureManagedPolicies)...)
+}
+
+func (r *RolePolicyAttachmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("attach role policy")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *RolePolicyAttachmentResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.iamCall(ctx, "AttachRolePolicy", url.Values{
+		"RoleName":  []string{data.Role.ValueString()},
+		"PolicyArn": []string{data.PolicyArn.ValueString()},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("could not attach role policy", err.Error())
+		return
+	}
+
+	// role names cannot contain ':', the policy arn follows the first one
+	data.Id = types.StringValue(fmt.Sprintf("%s:%s", data.Role.ValueString(), data.PolicyArn.ValueString()))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RolePolicyAttachmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *RolePolicyAttachmentResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	arns, err := r.client.listAttachedRolePolicies(ctx, data.Role.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not list attached role policies", err.Error())
+		return
+	}
+
+	attached := false
+	for _, arn := range arns {
+		if arn == data.PolicyArn.ValueString() {
+			attached = true
+			break
+		}
+	}
+	if !attached {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RolePolicyAttachmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update role policy attachment")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *RolePolicyAttachmentResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Currently there is nothing to update in place, all attributes require replacement
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RolePolicyAttachmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("detach role policy")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *RolePolicyAttachmentResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.iamCall(ctx, "DetachRolePolicy", url.Values{
+		"RoleName":  []string{data.Role.ValueString()},
+		"PolicyArn": []string{data.PolicyArn.ValueString()},
+	})
+	if err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("could not detach role policy", err.Error())
+		return
+	}
+}
+
+func (r *RolePolicyAttachmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	idx := strings.Index(req.ID, ":")
+	if idx < 1 || idx == len(req.ID)-1 {
+		resp.Diagnostics.AddError("invalid import id", fmt.Sprintf("expected '<role>:<policy_arn>', got '%s'", req.ID))
+		return
+	}
+
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("role"), req.ID[:idx])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("policy_arn"), req.ID[idx+1:])...)
+}
+
+// listAttachedRolePolicies lists the arns of the managed policies attached to a role
+func (c *RgwClient) listAttachedRolePolicies(ctx context.Context, role string) ([]string, error) {
+	var arns []string
+	marker := ""
+	for {
+		args := url.Values{"RoleName": []string{role}}
+		if marker != "" {
+			args.Set("Marker", marker)
+		}
+		body, err := c.iamCall(ctx, "ListAttachedRolePolicies", args)
+		if err != nil {
+			return nil, err
+		}
+
+		list := struct {
+			Arns        []string `xml:"ListAttachedRolePoliciesResult>AttachedPolicies>member>PolicyArn"`
+			IsTruncated bool     `xml:"ListAttachedRolePoliciesResult>IsTruncated"`
+			Marker      string   `xml:"ListAttachedRolePoliciesResult>Marker"`
+		}{}
+		if err := xml.Unmarshal(body, &list); err != nil {
+			return nil, err
+		}
+		arns = append(arns, list.Arns...)
+
+		if !list.IsTruncated || list.Marker == "" {
+			return arns, nil
+		}
+		marker = list.Marker
+	}
+}
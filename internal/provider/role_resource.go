@@ -0,0 +1,373 @@
+package provider
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// limits of roles enforced by rgw, which differ from aws
+const (
+	roleNameMaxLength          = 64
+	rolePathMaxLength          = 512
+	rolePolicyMaxLength        = 2048
+	roleSessionDurationMin     = 3600
+	roleSessionDurationMax     = 43200
+	defaultRoleSessionDuration = 3600
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &RoleResource{}
+var _ resource.ResourceWithModifyPlan = &RoleResource{}
+var _ resource.ResourceWithImportState = &RoleResource{}
+
+func NewRoleResource() resource.Resource {
+	return &RoleResource{}
+}
+
+type RoleResource struct {
+	client *RgwClient
+}
+
+type RoleResourceModel struct {
+	Id                 types.String `tfsdk:"id"`
+	Name               types.String `tfsdk:"name"`
+	Path               types.String `tfsdk:"path"`
+	AssumeRolePolicy   types.String `tfsdk:"assume_role_policy"`
+	MaxSessionDuration types.Int64  `tfsdk:"max_session_duration"`
+	Arn                types.String `tfsdk:"arn"`
+	RoleId             types.String `tfsdk:"role_id"`
+}
+
+func (r *RoleResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_role"
+}
+
+func (r *RoleResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "IAM role which can be assumed via STS, e.g. with `AssumeRoleWithWebIdentity` by users of an OpenID Connect provider. Roles belong to the tenant of the provider credentials, which need the `roles` admin cap.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Role Name, at most %d characters", roleNameMaxLength),
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, roleNameMaxLength),
+					stringvalidator.RegexMatches(regexp.MustCompile(`^[\w+=,.@-]+$`), "must only contain alphanumeric characters and '+=,.@-_'"),
+				},
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Path of the role, beginning and ending with `/`",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringDefaultModifier{"/"},
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, rolePathMaxLength),
+					stringvalidator.RegexMatches(regexp.MustCompile(`^/(.*/)?$`), "must begin and end with '/'"),
+				},
+			},
+			"assume_role_policy": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Trust policy document defining who may assume the role, at most %d characters", rolePolicyMaxLength),
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, rolePolicyMaxLength),
+					jsonValidator{},
+				},
+			},
+			"max_session_duration": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("Maximum duration of sessions of the role in seconds, between %d and %d", roleSessionDurationMin, roleSessionDurationMax),
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64DefaultModifier{defaultRoleSessionDuration},
+				},
+				Validators: []validator.Int64{
+					int64validator.Between(roleSessionDurationMin, roleSessionDurationMax),
+				},
+			},
+			"arn": schema.StringAttribute{
+				MarkdownDescription: "ARN of the role, used in `AssumeRole` calls",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"role_id": schema.StringAttribute{
+				MarkdownDescription: "Unique ID of the role",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *RoleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_role")...)
+}
+
+func (r *RoleResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "name", tenantOfCredentials)...)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	// let rgw parse changed trust policies on the validation role if configured
+	var data, state *RoleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() || data.AssumeRolePolicy.IsUnknown() {
+		return
+	}
+	if state == nil || !state.AssumeRolePolicy.Equal(data.AssumeRolePolicy) {
+		resp.Diagnostics.Append(r.client.validateAssumeRolePolicy(ctx, path.Root("assume_role_policy"), data.AssumeRolePolicy.ValueString())...)
+	}
+}
+
+func (r *RoleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create role")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *RoleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	args := url.Values{
+		"RoleName":                 []string{data.Name.ValueString()},
+		"Path":                     []string{data.Path.ValueString()},
+		"AssumeRolePolicyDocument": []string{data.AssumeRolePolicy.ValueString()},
+		"MaxSessionDuration":       []string{strconv.FormatInt(data.MaxSessionDuration.ValueInt64(), 10)},
+	}
+	// stamp default_labels as tags of the role
+	for i, k := range r.client.labelKeys() {
+		args.Set(fmt.Sprintf("Tags.member.%d.Key", i+1), k)
+		args.Set(fmt.Sprintf("Tags.member.%d.Value", i+1), r.client.DefaultLabels[k])
+	}
+
+	body, err := r.client.iamCall(ctx, "CreateRole", args)
+	if err != nil {
+		resp.Diagnostics.AddError("could not create role", err.Error())
+		return
+	}
+
+	role := iamRole{}
+	if err := xml.Unmarshal(body, &struct {
+		Role *iamRole `xml:"CreateRoleResult>Role"`
+	}{&role}); err != nil {
+		resp.Diagnostics.AddError("could not parse created role", err.Error())
+		return
+	}
+
+	// use role name as resource id
+	data.Id = data.Name
+	data.Arn = types.StringValue(role.Arn)
+	data.RoleId = types.StringValue(role.RoleId)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RoleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *RoleResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	role, err := r.client.getRole(ctx, data.Id.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get role", err.Error())
+		return
+	}
+
+	data.Name = types.StringValue(role.RoleName)
+	data.Path = types.StringValue(role.Path)
+	data.Arn = types.StringValue(role.Arn)
+	data.RoleId = types.StringValue(role.RoleId)
+	data.MaxSessionDuration = types.Int64Value(role.MaxSessionDuration)
+
+	// update trust policy, keep the configured spelling if it is the same json document
+	if !jsonEqual(role.AssumeRolePolicyDocument, data.AssumeRolePolicy.ValueString()) {
+		data.AssumeRolePolicy = types.StringValue(role.AssumeRolePolicyDocument)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_role", req.State, resp.State)...)
+}
+
+func (r *RoleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update role")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan and state data into the models
+	var data, state *RoleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.AssumeRolePolicy.Equal(state.AssumeRolePolicy) {
+		_, err := r.client.iamCall(ctx, "UpdateAssumeRolePolicy", url.Values{
+			"RoleName":       []string{data.Id.ValueString()},
+			"PolicyDocument": []string{data.AssumeRolePolicy.ValueString()},
+		})
+		if err != nil {
+			resp.Diagnostics.AddError("could not update trust policy of role", err.Error())
+			return
+		}
+	}
+
+	if !data.MaxSessionDuration.Equal(state.MaxSessionDuration) {
+		_, err := r.client.iamCall(ctx, "UpdateRole", url.Values{
+			"RoleName":           []string{data.Id.ValueString()},
+			"MaxSessionDuration": []string{strconv.FormatInt(data.MaxSessionDuration.ValueInt64(), 10)},
+		})
+		if err != nil {
+			resp.Diagnostics.AddError("could not update max session duration of role", err.Error())
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RoleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete role")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *RoleResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// rgw refuses to delete roles which still have permission policies
+	_, err := r.client.iamCall(ctx, "DeleteRole", url.Values{"RoleName": []string{data.Id.ValueString()}})
+	if err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("could not delete role", err.Error())
+		return
+	}
+}
+
+func (r *RoleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// iamRole is a role as returned by the iam api of rgw
+type iamRole struct {
+	RoleId                   string
+	RoleName                 string
+	Path                     string
+	Arn                      string
+	MaxSessionDuration       int64
+	AssumeRolePolicyDocument string
+}
+
+// getRole gets a role by its name
+func (c *RgwClient) getRole(ctx context.Context, name string) (*iamRole, error) {
+	body, err := c.iamCall(ctx, "GetRole", url.Values{"RoleName": []string{name}})
+	if err != nil {
+		return nil, err
+	}
+
+	role := &iamRole{}
+	if err := xml.Unmarshal(body, &struct {
+		Role *iamRole `xml:"GetRoleResult>Role"`
+	}{role}); err != nil {
+		return nil, err
+	}
+
+	// aws returns the policy url encoded, rgw versions differ
+	if !strings.HasPrefix(strings.TrimSpace(role.AssumeRolePolicyDocument), "{") {
+		if decoded, err := url.QueryUnescape(role.AssumeRolePolicyDocument); err == nil {
+			role.AssumeRolePolicyDocument = decoded
+		}
+	}
+
+	return role, nil
+}
+
+// jsonEqual checks whether two strings hold the same json document
+func jsonEqual(a string, b string) bool {
+	ca, err := canonicalJSON([]byte(a))
+	if err != nil {
+		return false
+	}
+	cb, err := canonicalJSON([]byte(b))
+	return err == nil && ca == cb
+}
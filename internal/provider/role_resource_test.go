@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestGetRoleDecodesPolicy(t *testing.T) {
+	policy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Federated":["arn:aws:iam:::oidc-provider/sso.example.com"]},"Action":["sts:AssumeRoleWithWebIdentity"]}]}`
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<GetRoleResponse><GetRoleResult><Role>` +
+			`<RoleId>8f41f4e0</RoleId><RoleName>ci</RoleName><Path>/</Path><Arn>arn:aws:iam:::role/ci</Arn>` +
+			`<MaxSessionDuration>7200</MaxSessionDuration>` +
+			`<AssumeRolePolicyDocument>` + url.QueryEscape(policy) + `</AssumeRolePolicyDocument>` +
+			`</Role></GetRoleResult></GetRoleResponse>`))
+	})
+
+	role, err := client.getRole(context.Background(), "ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if role.Arn != "arn:aws:iam:::role/ci" || role.MaxSessionDuration != 7200 {
+		t.Errorf("unexpected role %+v", role)
+	}
+	if role.AssumeRolePolicyDocument != policy {
+		t.Errorf("expected policy %s, got %s", policy, role.AssumeRolePolicyDocument)
+	}
+	if !jsonEqual(role.AssumeRolePolicyDocument, "{ \"Version\": \"2012-10-17\", \"Statement\": [{\"Action\": [\"sts:AssumeRoleWithWebIdentity\"], \"Effect\": \"Allow\", \"Principal\": {\"Federated\": [\"arn:aws:iam:::oidc-provider/sso.example.com\"]}}]}") {
+		t.Errorf("expected reformatted policy to be equal")
+	}
+}
@@ -0,0 +1,395 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &SubuserResource{}
+var _ resource.ResourceWithModifyPlan = &SubuserResource{}
+var _ resource.ResourceWithImportState = &SubuserResource{}
+
+func NewSubuserResource() resource.Resource {
+	return &SubuserResource{}
+}
+
+type SubuserResource struct {
+	client *RgwClient
+}
+
+type SubuserResourceModel struct {
+	Id             types.String `tfsdk:"id"`
+	UserId         types.String `tfsdk:"user_id"`
+	Subuser        types.String `tfsdk:"subuser"`
+	Access         types.String `tfsdk:"access"`
+	KeyType        types.String `tfsdk:"key_type"`
+	GenerateSecret types.Bool   `tfsdk:"generate_secret"`
+	AccessKey      types.String `tfsdk:"access_key"`
+	SecretKey      types.String `tfsdk:"secret_key"`
+}
+
+// the admin api replies with other access names than it accepts
+var subuserAccessFromReply = map[admin.SubuserAccess]admin.SubuserAccess{
+	admin.SubuserAccessReplyNone:      admin.SubuserAccessNone,
+	admin.SubuserAccessReplyRead:      admin.SubuserAccessRead,
+	admin.SubuserAccessReplyWrite:     admin.SubuserAccessWrite,
+	admin.SubuserAccessReplyReadWrite: admin.SubuserAccessReadWrite,
+	admin.SubuserAccessReplyFull:      admin.SubuserAccessFull,
+}
+
+func (r *SubuserResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_subuser"
+}
+
+func (r *SubuserResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Subuser of a Ceph RGW User, mostly used for Swift access.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The full subuser ID (`<user_id>:<subuser>`)",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"user_id": schema.StringAttribute{
+				MarkdownDescription: "The full user ID (`tenant$username` or `username`) the subuser belongs to, e.g. `rgw_user.example.id`.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"subuser": schema.StringAttribute{
+				MarkdownDescription: "The name of the subuser without the user ID prefix",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^[^:]+$`), "must not be empty or contain ':'"),
+				},
+			},
+			"access": schema.StringAttribute{
+				MarkdownDescription: "The access level of the subuser: `read`, `write`, `readwrite` or `full`",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(
+						string(admin.SubuserAccessRead),
+						string(admin.SubuserAccessWrite),
+						string(admin.SubuserAccessReadWrite),
+						string(admin.SubuserAccessFull),
+					),
+				},
+			},
+			"key_type": schema.StringAttribute{
+				MarkdownDescription: "The type of the generated key: `swift` or `s3` (default `swift`)",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringDefaultModifier{"swift"},
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.OneOf("swift", "s3"),
+				},
+			},
+			"generate_secret": schema.BoolAttribute{
+				MarkdownDescription: "Generate a key for the subuser (default `true`)",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Bool{
+					boolDefaultModifier{true},
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"access_key": schema.StringAttribute{
+				MarkdownDescription: "The generated access key, only set for `s3` keys",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"secret_key": schema.StringAttribute{
+				MarkdownDescription: "The generated secret key (the swift secret for `swift` keys)",
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *SubuserResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_subuser")...)
+}
+
+func (r *SubuserResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and user_id against user_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "user_id", tenantOfUser)...)
+	resp.Diagnostics.Append(r.client.planUserPrefix(ctx, req, "user_id")...)
+
+	// refuse subuser modifications of protected users
+	resp.Diagnostics.Append(r.client.planProtectedKey(ctx, req, resp)...)
+}
+
+func (r *SubuserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create subuser")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *SubuserResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	userId := data.UserId.ValueString()
+	subuserId := fmt.Sprintf("%s:%s", userId, data.Subuser.ValueString())
+
+	err := r.client.Admin.CreateSubuser(ctx, admin.User{ID: userId}, admin.SubuserSpec{
+		Name:   subuserId,
+		Access: admin.SubuserAccess(data.Access.ValueString()),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("could not create subuser", err.Error())
+		return
+	}
+
+	data.Id = types.StringValue(subuserId)
+	data.AccessKey = types.StringNull()
+	data.SecretKey = types.StringNull()
+
+	// the generate-secret flag of go-ceph never reaches the api, so the key is created separately
+	if data.GenerateSecret.ValueBool() {
+		generate := true
+		spec := admin.UserKeySpec{
+			UID:         userId,
+			SubUser:     subuserId,
+			KeyType:     data.KeyType.ValueString(),
+			GenerateKey: &generate,
+		}
+
+		// generate the access key ourselves, so the new key can be found in the returned key list
+		if spec.KeyType == "s3" {
+			spec.AccessKey, err = generateAccessKey()
+			if err != nil {
+				resp.Diagnostics.AddError("could not generate access key", err.Error())
+				return
+			}
+		}
+
+		keys, err := r.client.Admin.CreateKey(ctx, spec)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("could not create %s key", spec.KeyType), err.Error())
+			return
+		}
+
+		found := false
+		if keys != nil {
+			for _, k := range *keys {
+				if k.User == subuserId && (spec.KeyType != "s3" || k.AccessKey == spec.AccessKey) {
+					if spec.KeyType == "s3" {
+						data.AccessKey = types.StringValue(k.AccessKey)
+					}
+					data.SecretKey = types.StringValue(k.SecretKey)
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			resp.Diagnostics.AddError("api didn't return created key", fmt.Sprintf("key of subuser '%s' is missing in api response", subuserId))
+			return
+		}
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SubuserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *SubuserResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	user, err := r.client.Admin.GetUser(ctx, admin.User{ID: data.UserId.ValueString()})
+	if err != nil {
+		if errors.Is(err, admin.ErrNoSuchUser) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get user", err.Error())
+		return
+	}
+
+	// the subuser is gone if it isn't listed at the user anymore
+	subuserId := data.Id.ValueString()
+	found := false
+	for _, s := range user.Subusers {
+		if s.Name == subuserId {
+			access, ok := subuserAccessFromReply[s.Access]
+			if !ok {
+				access = s.Access
+			}
+			data.Access = types.StringValue(string(access))
+			found = true
+			break
+		}
+	}
+	if !found {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// refresh the key, imported subusers get their key type from the existing keys
+	data.AccessKey = types.StringNull()
+	data.SecretKey = types.StringNull()
+	for _, k := range user.SwiftKeys {
+		if k.User == subuserId && data.KeyType.ValueString() != "s3" {
+			data.KeyType = types.StringValue("swift")
+			data.SecretKey = types.StringValue(k.SecretKey)
+			break
+		}
+	}
+	if data.SecretKey.IsNull() {
+		for _, k := range user.Keys {
+			if k.User == subuserId && data.KeyType.ValueString() != "swift" {
+				data.KeyType = types.StringValue("s3")
+				data.AccessKey = types.StringValue(k.AccessKey)
+				data.SecretKey = types.StringValue(k.SecretKey)
+				break
+			}
+		}
+	}
+	if data.KeyType.IsNull() {
+		data.KeyType = types.StringValue("swift")
+	}
+	if data.GenerateSecret.IsNull() {
+		data.GenerateSecret = types.BoolValue(!data.SecretKey.IsNull())
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_subuser", req.State, resp.State)...)
+}
+
+func (r *SubuserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update subuser")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *SubuserResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// only the access level can be changed in place
+	err := r.client.Admin.ModifySubuser(ctx, admin.User{ID: data.UserId.ValueString()}, admin.SubuserSpec{
+		Name:   data.Id.ValueString(),
+		Access: admin.SubuserAccess(data.Access.ValueString()),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("could not modify subuser", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SubuserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete subuser")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *SubuserResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	purgeKeys := true
+	err := r.client.Admin.RemoveSubuser(ctx, admin.User{ID: data.UserId.ValueString()}, admin.SubuserSpec{
+		Name:      data.Id.ValueString(),
+		PurgeKeys: &purgeKeys,
+	})
+	if err != nil && !errors.Is(err, admin.ErrNoSuchUser) && !isNoSuchSubuser(err) {
+		resp.Diagnostics.AddError("could not delete subuser", err.Error())
+		return
+	}
+}
+
+func (r *SubuserResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// The import ID should be <user_id>:<subuser>
+	idx := strings.Index(req.ID, ":")
+	if idx < 1 || idx == len(req.ID)-1 {
+		resp.Diagnostics.AddError("invalid import id", fmt.Sprintf("expected '<user_id>:<subuser>', got '%s'", req.ID))
+		return
+	}
+
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("user_id"), req.ID[:idx])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("subuser"), req.ID[idx+1:])...)
+}
+
+// isNoSuchSubuser checks the error code, go-ceph doesn't export an error for missing subusers
+func isNoSuchSubuser(err error) bool {
+	return strings.HasPrefix(err.Error(), "NoSuchSubUser ")
+}
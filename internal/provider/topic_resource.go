@@ -0,0 +1,478 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &TopicResource{}
+var _ resource.ResourceWithModifyPlan = &TopicResource{}
+var _ resource.ResourceWithImportState = &TopicResource{}
+
+func NewTopicResource() resource.Resource {
+	return &TopicResource{}
+}
+
+type TopicResource struct {
+	client *RgwClient
+}
+
+type TopicResourceModel struct {
+	Id            types.String `tfsdk:"id"`
+	Name          types.String `tfsdk:"name"`
+	Arn           types.String `tfsdk:"arn"`
+	PushEndpoint  types.String `tfsdk:"push_endpoint"`
+	OpaqueData    types.String `tfsdk:"opaque_data"`
+	Persistent    types.Bool   `tfsdk:"persistent"`
+	VerifySsl     types.Bool   `tfsdk:"verify_ssl"`
+	UseSsl        types.Bool   `tfsdk:"use_ssl"`
+	CaLocation    types.String `tfsdk:"ca_location"`
+	AmqpExchange  types.String `tfsdk:"amqp_exchange"`
+	AmqpAckLevel  types.String `tfsdk:"amqp_ack_level"`
+	KafkaAckLevel types.String `tfsdk:"kafka_ack_level"`
+	Generation    types.String `tfsdk:"generation"`
+}
+
+// topicSchemes are the push endpoint schemes supported by rgw
+var topicSchemes = []string{"http", "https", "amqp", "amqps", "kafka"}
+
+func (r *TopicResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_topic"
+}
+
+func (r *TopicResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "SNS compatible topic bucket notifications are pushed to. Topics belong to the tenant of the provider credentials.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Topic Name",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 256),
+				},
+			},
+			"arn": schema.StringAttribute{
+				MarkdownDescription: "ARN of the topic, used in bucket notification configurations",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"push_endpoint": schema.StringAttribute{
+				MarkdownDescription: "Endpoint notifications are pushed to: `http[s]://host[:port][/path]`, `amqp[s]://[user:password@]host[:port][/vhost]` (AMQP 0.9.1) or `kafka://[user:password@]host[:port]`",
+				Required:            true,
+				Sensitive:           true,
+			},
+			"opaque_data": schema.StringAttribute{
+				MarkdownDescription: "Opaque data added to every notification of the topic",
+				Optional:            true,
+			},
+			"persistent": schema.BoolAttribute{
+				MarkdownDescription: "Queue notifications and push them asynchronously instead of blocking the request",
+				Optional:            true,
+			},
+			"verify_ssl": schema.BoolAttribute{
+				MarkdownDescription: "Verify the certificate of `https`, `amqps` and ssl enabled `kafka` endpoints, defaults to `true` in rgw",
+				Optional:            true,
+			},
+			"use_ssl": schema.BoolAttribute{
+				MarkdownDescription: "Connect to the kafka broker via ssl. Only used with `kafka` endpoints.",
+				Optional:            true,
+			},
+			"ca_location": schema.StringAttribute{
+				MarkdownDescription: "Path of the CA bundle on the rgw hosts used to verify `amqps` and `kafka` endpoints",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"amqp_exchange": schema.StringAttribute{
+				MarkdownDescription: "Exchange notifications are published to. Required with `amqp[s]` endpoints.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"amqp_ack_level": schema.StringAttribute{
+				MarkdownDescription: "Acknowledgement required from the amqp broker: `none`, `broker` or `routable`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("none", "broker", "routable"),
+				},
+			},
+			"kafka_ack_level": schema.StringAttribute{
+				MarkdownDescription: "Acknowledgement required from the kafka broker: `none` or `broker`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("none", "broker"),
+				},
+			},
+			"generation": schema.StringAttribute{
+				MarkdownDescription: "Changes whenever the topic is created again, unlike its `arn`. Bucket notifications referencing the topic are left dangling when it is deleted, so reference `generation` from them, e.g. in `replace_triggered_by`, to put them again after the topic was replaced.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *TopicResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_topic")...)
+}
+
+func (r *TopicResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "name", tenantOfCredentials)...)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var data *TopicResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.PushEndpoint.IsUnknown() {
+		return
+	}
+
+	// rgw accepts attributes of other endpoint types and silently ignores them
+	endpoint, err := url.Parse(data.PushEndpoint.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("push_endpoint"), "invalid push_endpoint", err.Error())
+		return
+	}
+	scheme := strings.ToLower(endpoint.Scheme)
+	amqp := scheme == "amqp" || scheme == "amqps"
+	kafka := scheme == "kafka"
+	switch {
+	case scheme == "http" || scheme == "https" || amqp || kafka:
+	default:
+		resp.Diagnostics.AddAttributeError(path.Root("push_endpoint"), "invalid push_endpoint",
+			fmt.Sprintf("unsupported scheme '%s', expected one of: %s", endpoint.Scheme, strings.Join(topicSchemes, ", ")))
+		return
+	}
+
+	if amqp && data.AmqpExchange.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("amqp_exchange"), "amqp_exchange required",
+			"amqp_exchange has to be set for amqp endpoints")
+	}
+	onlyWith := func(attribute string, value interface{ IsNull() bool }, allowed bool, endpoints string) {
+		if !allowed && !value.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root(attribute), attribute+" not allowed",
+				fmt.Sprintf("%s can only be set with %s endpoints", attribute, endpoints))
+		}
+	}
+	onlyWith("amqp_exchange", data.AmqpExchange, amqp, "amqp")
+	onlyWith("amqp_ack_level", data.AmqpAckLevel, amqp, "amqp")
+	onlyWith("kafka_ack_level", data.KafkaAckLevel, kafka, "kafka")
+	onlyWith("use_ssl", data.UseSsl, kafka, "kafka")
+	onlyWith("ca_location", data.CaLocation, amqp || kafka, "amqp or kafka")
+}
+
+func (r *TopicResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create topic")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *TopicResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	arn, err := r.client.putTopic(ctx, data)
+	if err != nil {
+		resp.Diagnostics.AddError("could not create topic", err.Error())
+		return
+	}
+
+	// use arn as resource id
+	data.Arn = types.StringValue(arn)
+	data.Id = data.Arn
+	data.Generation = newTopicGeneration()
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *TopicResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *TopicResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	topic, err := r.client.getTopic(ctx, data.Id.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get topic", err.Error())
+		return
+	}
+	topic.Id = data.Id
+
+	// the generation of imported topics starts with the import
+	topic.Generation = data.Generation
+	if topic.Generation.IsNull() {
+		topic.Generation = newTopicGeneration()
+	}
+
+	// default_labels stamped as opaque data are no drift
+	if data.OpaqueData.IsNull() && len(r.client.DefaultLabels) > 0 && topic.OpaqueData.ValueString() == r.client.labelsOpaqueData() {
+		topic.OpaqueData = types.StringNull()
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, topic)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_topic", req.State, resp.State)...)
+}
+
+func (r *TopicResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update topic")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *TopicResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// creating an existing topic replaces all of its attributes
+	_, err := r.client.putTopic(ctx, data)
+	if err != nil {
+		resp.Diagnostics.AddError("could not update topic", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *TopicResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete topic")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *TopicResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.snsCall(ctx, "DeleteTopic", url.Values{"TopicArn": []string{data.Id.ValueString()}})
+	if err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("could not delete topic", err.Error())
+		return
+	}
+}
+
+func (r *TopicResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// topicAttributes converts the model into the endpoint attributes of a topic
+func (m *TopicResourceModel) topicAttributes() map[string]string {
+	attributes := map[string]string{
+		"push-endpoint": m.PushEndpoint.ValueString(),
+	}
+	for key, value := range map[string]types.String{
+		"OpaqueData":      m.OpaqueData,
+		"ca-location":     m.CaLocation,
+		"amqp-exchange":   m.AmqpExchange,
+		"amqp-ack-level":  m.AmqpAckLevel,
+		"kafka-ack-level": m.KafkaAckLevel,
+	} {
+		if !value.IsNull() {
+			attributes[key] = value.ValueString()
+		}
+	}
+	for key, value := range map[string]types.Bool{
+		"persistent": m.Persistent,
+		"verify-ssl": m.VerifySsl,
+		"use-ssl":    m.UseSsl,
+	} {
+		if !value.IsNull() {
+			attributes[key] = strconv.FormatBool(value.ValueBool())
+		}
+	}
+	return attributes
+}
+
+// putTopic creates a topic or replaces the attributes of an existing one and
+// returns its arn
+func (c *RgwClient) putTopic(ctx context.Context, m *TopicResourceModel) (string, error) {
+	attributes := m.topicAttributes()
+	// stamp default_labels, unless opaque data is configured
+	if _, ok := attributes["OpaqueData"]; !ok && len(c.DefaultLabels) > 0 {
+		attributes["OpaqueData"] = c.labelsOpaqueData()
+	}
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	args := url.Values{"Name": []string{m.Name.ValueString()}}
+	for i, k := range keys {
+		args.Set(fmt.Sprintf("Attributes.entry.%d.key", i+1), k)
+		args.Set(fmt.Sprintf("Attributes.entry.%d.value", i+1), attributes[k])
+	}
+
+	body, err := c.snsCall(ctx, "CreateTopic", args)
+	if err != nil {
+		return "", err
+	}
+
+	result := struct {
+		Arn string `xml:"CreateTopicResult>TopicArn"`
+	}{}
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return "", err
+	}
+
+	return result.Arn, nil
+}
+
+// getTopic gets a topic by its arn
+func (c *RgwClient) getTopic(ctx context.Context, arn string) (*TopicResourceModel, error) {
+	body, err := c.snsCall(ctx, "GetTopicAttributes", url.Values{"TopicArn": []string{arn}})
+	if err != nil {
+		return nil, err
+	}
+
+	result := struct {
+		Entries []struct {
+			Key   string `xml:"key"`
+			Value string `xml:"value"`
+		} `xml:"GetTopicAttributesResult>Attributes>entry"`
+	}{}
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	attributes := make(map[string]string, len(result.Entries))
+	for _, entry := range result.Entries {
+		attributes[entry.Key] = entry.Value
+	}
+
+	// the endpoint holds the original arguments of CreateTopic as query string
+	endpoint := struct {
+		Address string `json:"EndpointAddress"`
+		Args    string `json:"EndpointArgs"`
+	}{}
+	if err := json.Unmarshal([]byte(attributes["EndPoint"]), &endpoint); err != nil {
+		return nil, fmt.Errorf("could not parse endpoint of topic: %w", err)
+	}
+	args, err := url.ParseQuery(endpoint.Args)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse endpoint arguments of topic: %w", err)
+	}
+
+	optionalArg := func(key string) types.String {
+		if !args.Has(key) {
+			return types.StringNull()
+		}
+		return types.StringValue(args.Get(key))
+	}
+	boolArg := func(key string) types.Bool {
+		if !args.Has(key) {
+			return types.BoolNull()
+		}
+		value, _ := strconv.ParseBool(args.Get(key))
+		return types.BoolValue(value)
+	}
+
+	topic := &TopicResourceModel{
+		Id:            types.StringValue(arn),
+		Name:          types.StringValue(attributes["Name"]),
+		Arn:           types.StringValue(arn),
+		PushEndpoint:  types.StringValue(endpoint.Address),
+		OpaqueData:    types.StringNull(),
+		Persistent:    boolArg("persistent"),
+		VerifySsl:     boolArg("verify-ssl"),
+		UseSsl:        boolArg("use-ssl"),
+		CaLocation:    optionalArg("ca-location"),
+		AmqpExchange:  optionalArg("amqp-exchange"),
+		AmqpAckLevel:  optionalArg("amqp-ack-level"),
+		KafkaAckLevel: optionalArg("kafka-ack-level"),
+	}
+	if opaque, ok := attributes["OpaqueData"]; ok && opaque != "" {
+		topic.OpaqueData = types.StringValue(opaque)
+	}
+	if topic.Name.ValueString() == "" {
+		topic.Name = types.StringValue(arn[strings.LastIndex(arn, ":")+1:])
+	}
+
+	return topic, nil
+}
+
+// newTopicGeneration returns the generation of a newly created topic, its
+// creation time
+func newTopicGeneration() types.String {
+	return types.StringValue(time.Now().UTC().Format(time.RFC3339Nano))
+}
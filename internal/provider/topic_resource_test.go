@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestPutTopicAttributes(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+			return
+		}
+		expected := map[string]string{
+			"Action":                   "CreateTopic",
+			"Name":                     "uploads",
+			"Attributes.entry.1.key":   "amqp-exchange",
+			"Attributes.entry.1.value": "ex",
+			"Attributes.entry.2.key":   "push-endpoint",
+			"Attributes.entry.2.value": "amqp://rabbit:5672",
+			"Attributes.entry.3.key":   "verify-ssl",
+			"Attributes.entry.3.value": "false",
+		}
+		for k, v := range expected {
+			if r.PostForm.Get(k) != v {
+				t.Errorf("expected %s=%q, got %q", k, v, r.PostForm.Get(k))
+			}
+		}
+		if r.PostForm.Has("Attributes.entry.4.key") {
+			t.Errorf("unexpected attribute %s", r.PostForm.Get("Attributes.entry.4.key"))
+		}
+		_, _ = w.Write([]byte(`<CreateTopicResponse><CreateTopicResult><TopicArn>arn:aws:sns:default::uploads</TopicArn></CreateTopicResult></CreateTopicResponse>`))
+	})
+
+	arn, err := client.putTopic(context.Background(), &TopicResourceModel{
+		Name:          types.StringValue("uploads"),
+		PushEndpoint:  types.StringValue("amqp://rabbit:5672"),
+		OpaqueData:    types.StringNull(),
+		Persistent:    types.BoolNull(),
+		VerifySsl:     types.BoolValue(false),
+		UseSsl:        types.BoolNull(),
+		CaLocation:    types.StringNull(),
+		AmqpExchange:  types.StringValue("ex"),
+		AmqpAckLevel:  types.StringNull(),
+		KafkaAckLevel: types.StringNull(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if arn != "arn:aws:sns:default::uploads" {
+		t.Errorf("unexpected arn %s", arn)
+	}
+}
+
+func TestGetTopicAttributes(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<GetTopicAttributesResponse><GetTopicAttributesResult><Attributes>` +
+			`<entry><key>User</key><value>notify</value></entry>` +
+			`<entry><key>Name</key><value>audit</value></entry>` +
+			`<entry><key>EndPoint</key><value>{"EndpointAddress":"kafka://kafka:9093","EndpointArgs":"Action=CreateTopic&amp;Name=audit&amp;push-endpoint=kafka://kafka:9093&amp;use-ssl=true&amp;kafka-ack-level=broker","EndpointTopic":"audit","HasStoredSecret":"false","Persistent":"false"}</value></entry>` +
+			`<entry><key>TopicArn</key><value>arn:aws:sns:default::audit</value></entry>` +
+			`<entry><key>OpaqueData</key><value></value></entry>` +
+			`</Attributes></GetTopicAttributesResult></GetTopicAttributesResponse>`))
+	})
+
+	topic, err := client.getTopic(context.Background(), "arn:aws:sns:default::audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if topic.Name.ValueString() != "audit" || topic.PushEndpoint.ValueString() != "kafka://kafka:9093" {
+		t.Errorf("unexpected topic %s with endpoint %s", topic.Name, topic.PushEndpoint)
+	}
+	if !topic.UseSsl.ValueBool() || topic.KafkaAckLevel.ValueString() != "broker" {
+		t.Errorf("unexpected kafka attributes use_ssl=%s kafka_ack_level=%s", topic.UseSsl, topic.KafkaAckLevel)
+	}
+	// attributes not passed to CreateTopic stay unset
+	if !topic.VerifySsl.IsNull() || !topic.Persistent.IsNull() || !topic.OpaqueData.IsNull() || !topic.AmqpExchange.IsNull() {
+		t.Errorf("unexpected attributes verify_ssl=%s persistent=%s opaque_data=%s amqp_exchange=%s",
+			topic.VerifySsl, topic.Persistent, topic.OpaqueData, topic.AmqpExchange)
+	}
+}
@@ -0,0 +1,198 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = &UsageSummaryDataSource{}
+
+func NewUsageSummaryDataSource() datasource.DataSource {
+	return &UsageSummaryDataSource{}
+}
+
+type UsageSummaryDataSource struct {
+	client *RgwClient
+}
+
+type UsageSummaryDataSourceModel struct {
+	Id            types.String `tfsdk:"id"`
+	Tenant        types.String `tfsdk:"tenant"`
+	AccountId     types.String `tfsdk:"account_id"`
+	Start         types.String `tfsdk:"start"`
+	End           types.String `tfsdk:"end"`
+	Users         []string     `tfsdk:"users"`
+	BytesSent     types.Int64  `tfsdk:"bytes_sent"`
+	BytesReceived types.Int64  `tfsdk:"bytes_received"`
+	Ops           types.Int64  `tfsdk:"ops"`
+	SuccessfulOps types.Int64  `tfsdk:"successful_ops"`
+}
+
+func (d *UsageSummaryDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_usage_summary"
+}
+
+func (d *UsageSummaryDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Usage of all users of a tenant or an account, aggregated from the usage log",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "The tenant to aggregate. If not set, users without tenant are aggregated.",
+				Optional:            true,
+			},
+			"account_id": schema.StringAttribute{
+				MarkdownDescription: "Aggregate the users of this account instead of a tenant. Requires Ceph >= 19.2 (Squid).",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("tenant")),
+				},
+			},
+			"start": schema.StringAttribute{
+				MarkdownDescription: "Start of the time window, e.g. `2023-01-01 00:00:00`",
+				Optional:            true,
+			},
+			"end": schema.StringAttribute{
+				MarkdownDescription: "End of the time window, e.g. `2023-02-01 00:00:00`",
+				Optional:            true,
+			},
+			"users": schema.ListAttribute{
+				MarkdownDescription: "IDs of the users with usage in the time window",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"bytes_sent": schema.Int64Attribute{
+				MarkdownDescription: "Total bytes sent",
+				Computed:            true,
+			},
+			"bytes_received": schema.Int64Attribute{
+				MarkdownDescription: "Total bytes received",
+				Computed:            true,
+			},
+			"ops": schema.Int64Attribute{
+				MarkdownDescription: "Total number of operations",
+				Computed:            true,
+			},
+			"successful_ops": schema.Int64Attribute{
+				MarkdownDescription: "Total number of successful operations",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *UsageSummaryDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "data.rgw_usage_summary")...)
+}
+
+func (d *UsageSummaryDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *UsageSummaryDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// users of an account belong to the tenant of the account, so only users
+	// of that tenant have to be looked up
+	tenant := data.Tenant.ValueString()
+	if !data.AccountId.IsNull() {
+		resp.Diagnostics.Append(d.client.requireFeature(featureAccounts)...)
+		resp.Diagnostics.Append(d.client.checkRequiredCaps(ctx, "data.rgw_usage_summary.account_id")...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		account, err := d.client.getAccount(ctx, data.AccountId.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("could not get account", err.Error())
+			return
+		}
+		tenant = account.Tenant
+	}
+
+	// only the summary is needed, entries can be huge
+	showEntries := false
+	showSummary := true
+	usage, err := d.client.Admin.GetUsage(ctx, admin.Usage{
+		Start:       data.Start.ValueString(),
+		End:         data.End.ValueString(),
+		ShowEntries: &showEntries,
+		ShowSummary: &showSummary,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("could not get usage", err.Error())
+		return
+	}
+
+	// group by tenant or account
+	var bytesSent, bytesReceived, ops, successfulOps uint64
+	data.Users = []string{}
+	for _, s := range usage.Summary {
+		if t, _ := tenantOfUser(s.User); t != tenant {
+			continue
+		}
+		if !data.AccountId.IsNull() {
+			accountId, err := d.client.accountOfUser(ctx, s.User)
+			if err != nil {
+				resp.Diagnostics.AddError("could not get account of user", err.Error())
+				return
+			}
+			if accountId != data.AccountId.ValueString() {
+				continue
+			}
+		}
+
+		data.Users = append(data.Users, s.User)
+		bytesSent += s.Total.BytesSent
+		bytesReceived += s.Total.BytesReceived
+		ops += s.Total.Ops
+		successfulOps += s.Total.SuccessfulOps
+	}
+	sort.Strings(data.Users)
+
+	scope := data.Tenant.ValueString()
+	if !data.AccountId.IsNull() {
+		scope = data.AccountId.ValueString()
+	}
+	data.Id = types.StringValue(fmt.Sprintf("%s/%s/%s", scope, data.Start.ValueString(), data.End.ValueString()))
+	data.BytesSent = types.Int64Value(int64(bytesSent))
+	data.BytesReceived = types.Int64Value(int64(bytesReceived))
+	data.Ops = types.Int64Value(int64(ops))
+	data.SuccessfulOps = types.Int64Value(int64(successfulOps))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = &UserDataSource{}
+
+func NewUserDataSource() datasource.DataSource {
+	return &UserDataSource{}
+}
+
+type UserDataSource struct {
+	client *RgwClient
+}
+
+type UserDataSourceModel struct {
+	Id           types.String   `tfsdk:"id"`
+	UserId       types.String   `tfsdk:"user_id"`
+	Username     types.String   `tfsdk:"username"`
+	Tenant       types.String   `tfsdk:"tenant"`
+	DisplayName  types.String   `tfsdk:"display_name"`
+	Email        types.String   `tfsdk:"email"`
+	Caps         []UserCapModel `tfsdk:"caps"`
+	OpMask       types.String   `tfsdk:"op_mask"`
+	MaxBuckets   types.Int64    `tfsdk:"max_buckets"`
+	Suspended    types.Bool     `tfsdk:"suspended"`
+	Principal    types.String   `tfsdk:"principal"`
+	CreateDate   types.String   `tfsdk:"create_date"`
+	LastModified types.String   `tfsdk:"last_modified"`
+	UserInfo     types.Object   `tfsdk:"user_info"`
+}
+
+func (d *UserDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user"
+}
+
+func (d *UserDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Ceph RGW User",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"user_id": schema.StringAttribute{
+				MarkdownDescription: "The full user ID (`tenant$username` or `username`).",
+				Required:            true,
+			},
+			"username": schema.StringAttribute{
+				MarkdownDescription: "The user ID without tenant",
+				Computed:            true,
+			},
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "The tenant under which a user is a part of.",
+				Computed:            true,
+			},
+			"display_name": schema.StringAttribute{
+				MarkdownDescription: "Display Name of user",
+				Computed:            true,
+			},
+			"email": schema.StringAttribute{
+				MarkdownDescription: "The email address associated with the user.",
+				Computed:            true,
+			},
+			"caps": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							Computed: true,
+						},
+						"perm": schema.StringAttribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+			"op_mask": schema.StringAttribute{
+				MarkdownDescription: "The op-mask of the user",
+				Computed:            true,
+			},
+			"max_buckets": schema.Int64Attribute{
+				MarkdownDescription: "The maximum number of buckets the user can own.",
+				Computed:            true,
+			},
+			"suspended": schema.BoolAttribute{
+				MarkdownDescription: "Whether the user is suspended.",
+				Computed:            true,
+			},
+			"principal": schema.StringAttribute{
+				MarkdownDescription: "Computed principal to be used in policies",
+				Computed:            true,
+			},
+			"create_date": schema.StringAttribute{
+				MarkdownDescription: "Creation date of the user as reported by the metadata api. Empty on releases not recording it.",
+				Computed:            true,
+			},
+			"last_modified": schema.StringAttribute{
+				MarkdownDescription: "Last modification time of the user metadata",
+				Computed:            true,
+			},
+			"user_info": schema.ObjectAttribute{
+				MarkdownDescription: userInfoDescription,
+				AttributeTypes:      userInfoAttributeTypes,
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *UserDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "data.rgw_user")...)
+}
+
+func (d *UserDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *UserDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// get user
+	user, err := d.client.Admin.GetUser(ctx, admin.User{ID: data.UserId.ValueString()})
+	if err != nil {
+		resp.Diagnostics.AddError("could not get user", err.Error())
+		return
+	}
+
+	data.Id = data.UserId
+
+	// split user id into tenant and username
+	splittedId := strings.SplitN(data.UserId.ValueString(), "$", 2)
+	if len(splittedId) == 2 {
+		data.Tenant = types.StringValue(splittedId[0])
+		data.Username = types.StringValue(splittedId[1])
+		data.Principal = types.StringValue(fmt.Sprintf("arn:aws:iam::%s:user/%s", splittedId[0], splittedId[1]))
+	} else {
+		data.Tenant = types.StringNull()
+		data.Username = types.StringValue(splittedId[0])
+		data.Principal = types.StringValue(fmt.Sprintf("arn:aws:iam:::user/%s", splittedId[0]))
+	}
+
+	data.DisplayName = types.StringValue(user.DisplayName)
+	data.Email = types.StringValue(user.Email)
+	data.OpMask = types.StringValue(user.OpMask)
+
+	data.Caps = make([]UserCapModel, len(user.Caps))
+	for i, c := range user.Caps {
+		data.Caps[i].Type = types.StringValue(c.Type)
+		data.Caps[i].Perm = types.StringValue(c.Perm)
+	}
+
+	if user.MaxBuckets != nil {
+		data.MaxBuckets = types.Int64Value(int64(*user.MaxBuckets))
+	}
+
+	data.Suspended = types.BoolValue(user.Suspended != nil && *user.Suspended > 0)
+
+	userInfo, diags := userInfoFromApi(user)
+	resp.Diagnostics.Append(diags...)
+	data.UserInfo = userInfo
+
+	// get metadata timestamps
+	meta, err := d.client.getUserMetadata(ctx, data.UserId.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("could not get user metadata", err.Error())
+		return
+	}
+	data.CreateDate = types.StringValue(meta.Data.CreateDate)
+	data.LastModified = types.StringValue(meta.Mtime)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
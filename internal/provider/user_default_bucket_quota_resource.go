@@ -0,0 +1,240 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &UserDefaultBucketQuotaResource{}
+var _ resource.ResourceWithModifyPlan = &UserDefaultBucketQuotaResource{}
+var _ resource.ResourceWithImportState = &UserDefaultBucketQuotaResource{}
+
+func NewUserDefaultBucketQuotaResource() resource.Resource {
+	return &UserDefaultBucketQuotaResource{}
+}
+
+type UserDefaultBucketQuotaResource struct {
+	client *RgwClient
+}
+
+type UserDefaultBucketQuotaResourceModel struct {
+	Id         types.String `tfsdk:"id"`
+	UserId     types.String `tfsdk:"user_id"`
+	Enabled    types.Bool   `tfsdk:"enabled"`
+	MaxSizeKb  types.Int64  `tfsdk:"max_size_kb"`
+	MaxObjects types.Int64  `tfsdk:"max_objects"`
+}
+
+func (r *UserDefaultBucketQuotaResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_default_bucket_quota"
+}
+
+func (r *UserDefaultBucketQuotaResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Default bucket quota of a Ceph RGW User, applied to each bucket the user owns. Do not combine with `bucket_quota` of the same `rgw_user`. On destroy the bucket quota of the user is disabled.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"user_id": schema.StringAttribute{
+				MarkdownDescription: "The full user ID (`tenant$username` or `username`), e.g. `rgw_user.example.id`.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				MarkdownDescription: "Enable or disable bucket quota",
+				Required:            true,
+			},
+			"max_size_kb": schema.Int64Attribute{
+				MarkdownDescription: "Maximum size of each bucket in KB. If not set or -1, it means unlimited.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64DefaultModifier{-1},
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"max_objects": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of objects in each bucket. If not set or -1, it means unlimited.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64DefaultModifier{-1},
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *UserDefaultBucketQuotaResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_user_default_bucket_quota")...)
+}
+
+func (r *UserDefaultBucketQuotaResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and user_id against user_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "user_id", tenantOfUser)...)
+	resp.Diagnostics.Append(r.client.planUserPrefix(ctx, req, "user_id")...)
+}
+
+func (r *UserDefaultBucketQuotaResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create user default bucket quota")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *UserDefaultBucketQuotaResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.setQuota(ctx, data.UserId.ValueString(), "bucket", data.quota())
+	if err != nil {
+		resp.Diagnostics.AddError("could not set bucket quota", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(r.client.verifyUserQuota(ctx, data.UserId.ValueString(), "bucket", data.quota())...)
+
+	data.Id = data.UserId
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *UserDefaultBucketQuotaResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *UserDefaultBucketQuotaResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	quota, err := r.client.getQuota(ctx, data.UserId.ValueString(), "bucket")
+	if err != nil {
+		if errors.Is(err, admin.ErrNoSuchUser) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get bucket quota", err.Error())
+		return
+	}
+
+	data.Enabled = quota.Enabled
+	data.MaxSizeKb = quota.MaxSizeKb
+	data.MaxObjects = quota.MaxObjects
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_user_default_bucket_quota", req.State, resp.State)...)
+}
+
+func (r *UserDefaultBucketQuotaResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update user default bucket quota")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *UserDefaultBucketQuotaResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.setQuota(ctx, data.UserId.ValueString(), "bucket", data.quota())
+	if err != nil {
+		resp.Diagnostics.AddError("could not set bucket quota", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(r.client.verifyUserQuota(ctx, data.UserId.ValueString(), "bucket", data.quota())...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *UserDefaultBucketQuotaResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete user default bucket quota")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *UserDefaultBucketQuotaResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// a quota can't be removed, reset it to disabled and unlimited instead
+	err := r.client.setQuota(ctx, data.UserId.ValueString(), "bucket", &UserQuotaModel{
+		Enabled:    types.BoolValue(false),
+		MaxSizeKb:  types.Int64Value(-1),
+		MaxObjects: types.Int64Value(-1),
+	})
+	if err != nil && !errors.Is(err, admin.ErrNoSuchUser) {
+		resp.Diagnostics.AddError("could not reset bucket quota", err.Error())
+		return
+	}
+}
+
+func (r *UserDefaultBucketQuotaResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("user_id"), req.ID)...)
+}
+
+// quota converts the model into the quota model shared with rgw_user
+func (m *UserDefaultBucketQuotaResourceModel) quota() *UserQuotaModel {
+	return &UserQuotaModel{
+		Enabled:    m.Enabled,
+		MaxSizeKb:  m.MaxSizeKb,
+		MaxObjects: m.MaxObjects,
+	}
+}
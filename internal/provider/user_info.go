@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var userInfoQuotaType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"enabled":     types.BoolType,
+	"max_size_kb": types.Int64Type,
+	"max_objects": types.Int64Type,
+}}
+
+var userInfoCapType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"type": types.StringType,
+	"perm": types.StringType,
+}}
+
+var userInfoSubuserType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"id":          types.StringType,
+	"permissions": types.StringType,
+}}
+
+// userInfoAttributeTypes describes the user_info attribute, the normalized
+// user info of the admin api with all secrets removed
+var userInfoAttributeTypes = map[string]attr.Type{
+	"user_id":               types.StringType,
+	"display_name":          types.StringType,
+	"email":                 types.StringType,
+	"suspended":             types.BoolType,
+	"max_buckets":           types.Int64Type,
+	"op_mask":               types.StringType,
+	"type":                  types.StringType,
+	"default_placement":     types.StringType,
+	"default_storage_class": types.StringType,
+	"access_keys":           types.ListType{ElemType: types.StringType},
+	"swift_users":           types.ListType{ElemType: types.StringType},
+	"subusers":              types.ListType{ElemType: userInfoSubuserType},
+	"caps":                  types.ListType{ElemType: userInfoCapType},
+	"user_quota":            userInfoQuotaType,
+	"bucket_quota":          userInfoQuotaType,
+}
+
+const userInfoDescription = "The full user info as returned by the admin api, normalized and with all secret keys removed. Contains `user_id`, `display_name`, `email`, `suspended`, `max_buckets`, `op_mask`, `type`, `default_placement`, `default_storage_class`, `access_keys`, `swift_users`, `subusers`, `caps`, `user_quota` and `bucket_quota`."
+
+// userInfoFromApi converts the user info of the admin api to the user_info attribute
+func userInfoFromApi(user admin.User) (types.Object, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	accessKeys := make([]attr.Value, len(user.Keys))
+	for i, k := range user.Keys {
+		accessKeys[i] = types.StringValue(k.AccessKey)
+	}
+
+	swiftUsers := make([]attr.Value, len(user.SwiftKeys))
+	for i, k := range user.SwiftKeys {
+		swiftUsers[i] = types.StringValue(k.User)
+	}
+
+	subusers := make([]attr.Value, len(user.Subusers))
+	for i, s := range user.Subusers {
+		v, d := types.ObjectValue(userInfoSubuserType.AttrTypes, map[string]attr.Value{
+			"id":          types.StringValue(s.Name),
+			"permissions": types.StringValue(string(s.Access)),
+		})
+		diags.Append(d...)
+		subusers[i] = v
+	}
+
+	caps := make([]attr.Value, len(user.Caps))
+	for i, c := range user.Caps {
+		v, d := types.ObjectValue(userInfoCapType.AttrTypes, map[string]attr.Value{
+			"type": types.StringValue(c.Type),
+			"perm": types.StringValue(c.Perm),
+		})
+		diags.Append(d...)
+		caps[i] = v
+	}
+
+	userQuota, d := userInfoQuota(user.UserQuota)
+	diags.Append(d...)
+	bucketQuota, d := userInfoQuota(user.BucketQuota)
+	diags.Append(d...)
+
+	suspended := user.Suspended != nil && *user.Suspended > 0
+	maxBuckets := types.Int64Null()
+	if user.MaxBuckets != nil {
+		maxBuckets = types.Int64Value(int64(*user.MaxBuckets))
+	}
+
+	info, d := types.ObjectValue(userInfoAttributeTypes, map[string]attr.Value{
+		"user_id":               types.StringValue(user.ID),
+		"display_name":          types.StringValue(user.DisplayName),
+		"email":                 types.StringValue(user.Email),
+		"suspended":             types.BoolValue(suspended),
+		"max_buckets":           maxBuckets,
+		"op_mask":               types.StringValue(user.OpMask),
+		"type":                  types.StringValue(user.Type),
+		"default_placement":     types.StringValue(user.DefaultPlacement),
+		"default_storage_class": types.StringValue(user.DefaultStorageClass),
+		"access_keys":           types.ListValueMust(types.StringType, accessKeys),
+		"swift_users":           types.ListValueMust(types.StringType, swiftUsers),
+		"subusers":              types.ListValueMust(userInfoSubuserType, subusers),
+		"caps":                  types.ListValueMust(userInfoCapType, caps),
+		"user_quota":            userQuota,
+		"bucket_quota":          bucketQuota,
+	})
+	diags.Append(d...)
+
+	return info, diags
+}
+
+// userInfoQuota converts a quota of the user info to an object value
+func userInfoQuota(q admin.QuotaSpec) (types.Object, diag.Diagnostics) {
+	enabled := q.Enabled != nil && *q.Enabled
+	maxSizeKb := int64(-1)
+	if q.MaxSizeKb != nil {
+		maxSizeKb = int64(*q.MaxSizeKb)
+	}
+	maxObjects := int64(-1)
+	if q.MaxObjects != nil {
+		maxObjects = *q.MaxObjects
+	}
+
+	return types.ObjectValue(userInfoQuotaType.AttrTypes, map[string]attr.Value{
+		"enabled":     types.BoolValue(enabled),
+		"max_size_kb": types.Int64Value(maxSizeKb),
+		"max_objects": types.Int64Value(maxObjects),
+	})
+}
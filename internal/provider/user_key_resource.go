@@ -0,0 +1,269 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &UserKeyResource{}
+var _ resource.ResourceWithModifyPlan = &UserKeyResource{}
+var _ resource.ResourceWithImportState = &UserKeyResource{}
+
+func NewUserKeyResource() resource.Resource {
+	return &UserKeyResource{}
+}
+
+type UserKeyResource struct {
+	client *RgwClient
+}
+
+type UserKeyResourceModel struct {
+	Id        types.String `tfsdk:"id"`
+	UserId    types.String `tfsdk:"user_id"`
+	AccessKey types.String `tfsdk:"access_key"`
+	SecretKey types.String `tfsdk:"secret_key"`
+}
+
+func (r *UserKeyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_key"
+}
+
+func (r *UserKeyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "S3 key pair of a Ceph RGW User. The key is managed independently of the user, so `terraform apply -replace` on this resource rotates the key without touching the `rgw_user`. The `rgw_user` never takes over keys of this resource and its `exclusive_s3_credentials` is not changed by them, so adding or rotating keys doesn't change the plan of the user.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"user_id": schema.StringAttribute{
+				MarkdownDescription: "The full user ID (`tenant$username` or `username`) the key belongs to, e.g. `rgw_user.example.id`.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"access_key": schema.StringAttribute{
+				MarkdownDescription: "The generated access key",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"secret_key": schema.StringAttribute{
+				MarkdownDescription: "The generated secret key",
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *UserKeyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_user_key")...)
+}
+
+func (r *UserKeyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and user_id against user_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "user_id", tenantOfUser)...)
+	resp.Diagnostics.Append(r.client.planUserPrefix(ctx, req, "user_id")...)
+
+	// refuse key modifications of protected users
+	resp.Diagnostics.Append(r.client.planProtectedKey(ctx, req, resp)...)
+
+	// refuse keys beyond max_user_keys before rgw rejects them
+	resp.Diagnostics.Append(r.client.planMaxUserKeys(ctx, req, resp)...)
+}
+
+func (r *UserKeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create user key")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *UserKeyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// generate the access key ourselves, so the new key can be found in the returned key list
+	accessKey, err := generateAccessKey()
+	if err != nil {
+		resp.Diagnostics.AddError("could not generate access key", err.Error())
+		return
+	}
+
+	generate := true
+	keys, err := r.client.Admin.CreateKey(ctx, admin.UserKeySpec{
+		UID:         data.UserId.ValueString(),
+		KeyType:     "s3",
+		GenerateKey: &generate,
+		AccessKey:   accessKey,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("could not create s3 key", err.Error())
+		return
+	}
+
+	found := false
+	if keys != nil {
+		for _, k := range *keys {
+			if k.AccessKey == accessKey {
+				data.AccessKey = types.StringValue(k.AccessKey)
+				data.SecretKey = types.StringValue(k.SecretKey)
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		resp.Diagnostics.AddError("api didn't return created key", fmt.Sprintf("access key '%s' is missing in api response", accessKey))
+		return
+	}
+
+	data.Id = types.StringValue(fmt.Sprintf("%s:%s", data.UserId.ValueString(), accessKey))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *UserKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *UserKeyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	user, err := r.client.Admin.GetUser(ctx, admin.User{ID: data.UserId.ValueString()})
+	if err != nil {
+		if errors.Is(err, admin.ErrNoSuchUser) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get user", err.Error())
+		return
+	}
+
+	// the key is gone if it isn't part of the users keyring anymore
+	found := false
+	for _, k := range user.Keys {
+		if k.AccessKey == data.AccessKey.ValueString() {
+			data.SecretKey = types.StringValue(k.SecretKey)
+			found = true
+			break
+		}
+	}
+	if !found {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_user_key", req.State, resp.State)...)
+}
+
+func (r *UserKeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update user key")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *UserKeyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Currently there is nothing to update in place
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *UserKeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete user key")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *UserKeyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.Admin.RemoveKey(ctx, admin.UserKeySpec{
+		UID:       data.UserId.ValueString(),
+		KeyType:   "s3",
+		AccessKey: data.AccessKey.ValueString(),
+	})
+	if err != nil && !errors.Is(err, admin.ErrNoSuchUser) && !errors.Is(err, admin.ErrInvalidAccessKey) {
+		resp.Diagnostics.AddError("could not delete s3 key", err.Error())
+		return
+	}
+}
+
+func (r *UserKeyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// The import ID should be <user_id>:<access_key>
+	idx := strings.LastIndex(req.ID, ":")
+	if idx < 1 || idx == len(req.ID)-1 {
+		resp.Diagnostics.AddError("invalid import id", fmt.Sprintf("expected '<user_id>:<access_key>', got '%s'", req.ID))
+		return
+	}
+
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("user_id"), req.ID[:idx])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("access_key"), req.ID[idx+1:])...)
+}
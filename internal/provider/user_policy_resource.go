@@ -0,0 +1,271 @@
+package provider
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = &UserPolicyResource{}
+var _ resource.ResourceWithModifyPlan = &UserPolicyResource{}
+var _ resource.ResourceWithImportState = &UserPolicyResource{}
+
+func NewUserPolicyResource() resource.Resource {
+	return &UserPolicyResource{}
+}
+
+type UserPolicyResource struct {
+	client *RgwClient
+}
+
+type UserPolicyResourceModel struct {
+	Id     types.String `tfsdk:"id"`
+	UserId types.String `tfsdk:"user_id"`
+	Name   types.String `tfsdk:"name"`
+	Policy types.String `tfsdk:"policy"`
+}
+
+func (r *UserPolicyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_policy"
+}
+
+func (r *UserPolicyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Inline IAM policy of a Ceph RGW User, granting permissions to the user directly instead of via bucket policies",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"user_id": schema.StringAttribute{
+				MarkdownDescription: "The full user ID (`tenant$username` or `username`).",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the policy, unique per user",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 128),
+					stringvalidator.RegexMatches(regexp.MustCompile(`^[\w+=,.@-]+$`), "may only contain alphanumeric characters and +=,.@-_"),
+				},
+			},
+			"policy": schema.StringAttribute{
+				MarkdownDescription: "Policy document. Condition keys not evaluated by RGW (e.g. `aws:SourceVpc`) produce a warning.",
+				Required:            true,
+				Validators: []validator.String{
+					policyConditionValidator{},
+				},
+			},
+		},
+	}
+}
+
+func (r *UserPolicyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_user_policy")...)
+}
+
+func (r *UserPolicyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants and user_id against user_prefix, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "user_id", tenantOfUser)...)
+	resp.Diagnostics.Append(r.client.planUserPrefix(ctx, req, "user_id")...)
+}
+
+func (r *UserPolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create user policy")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *UserPolicyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.putUserPolicy(ctx, data); err != nil {
+		resp.Diagnostics.AddError("could not put user policy", err.Error())
+		return
+	}
+
+	// user IDs cannot contain ':', the policy name follows the first one
+	data.Id = types.StringValue(fmt.Sprintf("%s:%s", data.UserId.ValueString(), data.Name.ValueString()))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *UserPolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Read Terraform prior state data into the model
+	var data *UserPolicyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	policy, err := r.client.getUserPolicy(ctx, data.UserId.ValueString(), data.Name.ValueString())
+	if err != nil {
+		if isNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("could not get user policy", err.Error())
+		return
+	}
+
+	// update policy, keep the configured spelling if it is the same json document
+	if !jsonEqual(policy, data.Policy.ValueString()) {
+		data.Policy = types.StringValue(policy)
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_user_policy", req.State, resp.State)...)
+}
+
+func (r *UserPolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update user policy")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform plan data into the model
+	var data *UserPolicyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// PutUserPolicy replaces the policy of the same name
+	if err := r.client.putUserPolicy(ctx, data); err != nil {
+		resp.Diagnostics.AddError("could not put user policy", err.Error())
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *UserPolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete user policy")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read Terraform prior state data into the model
+	var data *UserPolicyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.iamCall(ctx, "DeleteUserPolicy", url.Values{
+		"UserName":   []string{data.UserId.ValueString()},
+		"PolicyName": []string{data.Name.ValueString()},
+	})
+	if err != nil && !isNotFound(err) {
+		resp.Diagnostics.AddError("could not delete user policy", err.Error())
+		return
+	}
+}
+
+func (r *UserPolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	idx := strings.Index(req.ID, ":")
+	if idx < 1 || idx == len(req.ID)-1 {
+		resp.Diagnostics.AddError("invalid import id", fmt.Sprintf("expected '<user_id>:<name>', got '%s'", req.ID))
+		return
+	}
+
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("user_id"), req.ID[:idx])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), req.ID[idx+1:])...)
+}
+
+// putUserPolicy creates or replaces an inline policy of a user
+func (c *RgwClient) putUserPolicy(ctx context.Context, m *UserPolicyResourceModel) error {
+	_, err := c.iamCall(ctx, "PutUserPolicy", url.Values{
+		"UserName":       []string{m.UserId.ValueString()},
+		"PolicyName":     []string{m.Name.ValueString()},
+		"PolicyDocument": []string{m.Policy.ValueString()},
+	})
+	return err
+}
+
+// getUserPolicy gets the document of an inline policy of a user
+func (c *RgwClient) getUserPolicy(ctx context.Context, userId string, name string) (string, error) {
+	body, err := c.iamCall(ctx, "GetUserPolicy", url.Values{
+		"UserName":   []string{userId},
+		"PolicyName": []string{name},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	result := struct {
+		PolicyDocument string `xml:"GetUserPolicyResult>PolicyDocument"`
+	}{}
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return "", err
+	}
+
+	// aws returns the policy url encoded, rgw versions differ
+	policy := result.PolicyDocument
+	if !strings.HasPrefix(strings.TrimSpace(policy), "{") {
+		if decoded, err := url.QueryUnescape(policy); err == nil {
+			policy = decoded
+		}
+	}
+
+	return policy, nil
+}
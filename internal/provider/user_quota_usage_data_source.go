@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = &UserQuotaUsageDataSource{}
+
+func NewUserQuotaUsageDataSource() datasource.DataSource {
+	return &UserQuotaUsageDataSource{}
+}
+
+type UserQuotaUsageDataSource struct {
+	client *RgwClient
+}
+
+type UserQuotaUsageDataSourceModel struct {
+	Id                 types.String  `tfsdk:"id"`
+	UserId             types.String  `tfsdk:"user_id"`
+	ThresholdPercent   types.Float64 `tfsdk:"threshold_percent"`
+	QuotaEnabled       types.Bool    `tfsdk:"quota_enabled"`
+	Size               types.Int64   `tfsdk:"size"`
+	NumObjects         types.Int64   `tfsdk:"num_objects"`
+	MaxSize            types.Int64   `tfsdk:"max_size"`
+	MaxObjects         types.Int64   `tfsdk:"max_objects"`
+	SizeUtilization    types.Float64 `tfsdk:"size_utilization_percent"`
+	ObjectsUtilization types.Float64 `tfsdk:"objects_utilization_percent"`
+	OverThreshold      types.Bool    `tfsdk:"over_threshold"`
+}
+
+func (d *UserQuotaUsageDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_quota_usage"
+}
+
+func (d *UserQuotaUsageDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Compares the current usage of a Ceph RGW User with its user quota. The usage is taken from the user stats, which rgw updates asynchronously.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"user_id": schema.StringAttribute{
+				MarkdownDescription: "The full user ID (`tenant$username` or `username`).",
+				Required:            true,
+			},
+			"threshold_percent": schema.Float64Attribute{
+				MarkdownDescription: "Utilization in percent from which on `over_threshold` is set. Defaults to `80`.",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.Float64{
+					float64validator.Between(0, 100),
+				},
+			},
+			"quota_enabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether the user quota is enabled",
+				Computed:            true,
+			},
+			"size": schema.Int64Attribute{
+				MarkdownDescription: "The size of all objects of the user in bytes",
+				Computed:            true,
+			},
+			"num_objects": schema.Int64Attribute{
+				MarkdownDescription: "The number of objects of the user",
+				Computed:            true,
+			},
+			"max_size": schema.Int64Attribute{
+				MarkdownDescription: "The maximum size of the user quota in bytes, -1 if unlimited",
+				Computed:            true,
+			},
+			"max_objects": schema.Int64Attribute{
+				MarkdownDescription: "The maximum number of objects of the user quota, -1 if unlimited",
+				Computed:            true,
+			},
+			"size_utilization_percent": schema.Float64Attribute{
+				MarkdownDescription: "Used size in percent of `max_size`. Null if the quota is disabled or the size unlimited.",
+				Computed:            true,
+			},
+			"objects_utilization_percent": schema.Float64Attribute{
+				MarkdownDescription: "Used objects in percent of `max_objects`. Null if the quota is disabled or the objects unlimited.",
+				Computed:            true,
+			},
+			"over_threshold": schema.BoolAttribute{
+				MarkdownDescription: "Whether the size or objects utilization reached `threshold_percent`",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *UserQuotaUsageDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "data.rgw_user_quota_usage")...)
+}
+
+func (d *UserQuotaUsageDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *UserQuotaUsageDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.ThresholdPercent.IsNull() {
+		data.ThresholdPercent = types.Float64Value(80)
+	}
+
+	// get user including its stats
+	stats := true
+	user, err := d.client.Admin.GetUser(ctx, admin.User{ID: data.UserId.ValueString(), GenerateStat: &stats})
+	if err != nil {
+		resp.Diagnostics.AddError("could not get user", err.Error())
+		return
+	}
+
+	data.Id = data.UserId
+
+	var size, numObjects int64
+	if user.Stat.Size != nil {
+		size = int64(*user.Stat.Size)
+	}
+	if user.Stat.NumObjects != nil {
+		numObjects = int64(*user.Stat.NumObjects)
+	}
+	data.Size = types.Int64Value(size)
+	data.NumObjects = types.Int64Value(numObjects)
+
+	quota := user.UserQuota
+	data.QuotaEnabled = types.BoolValue(quota.Enabled != nil && *quota.Enabled)
+
+	maxSize := int64(-1)
+	if quota.MaxSize != nil {
+		maxSize = *quota.MaxSize
+	} else if quota.MaxSizeKb != nil && *quota.MaxSizeKb >= 0 {
+		maxSize = int64(*quota.MaxSizeKb) * 1024
+	}
+	maxObjects := int64(-1)
+	if quota.MaxObjects != nil {
+		maxObjects = *quota.MaxObjects
+	}
+	data.MaxSize = types.Int64Value(maxSize)
+	data.MaxObjects = types.Int64Value(maxObjects)
+
+	// utilization is only defined for enabled and limited quotas
+	data.SizeUtilization = types.Float64Null()
+	data.ObjectsUtilization = types.Float64Null()
+	data.OverThreshold = types.BoolValue(false)
+	threshold := data.ThresholdPercent.ValueFloat64()
+	if data.QuotaEnabled.ValueBool() {
+		if maxSize > 0 {
+			data.SizeUtilization = types.Float64Value(float64(size) * 100 / float64(maxSize))
+			if data.SizeUtilization.ValueFloat64() >= threshold {
+				data.OverThreshold = types.BoolValue(true)
+			}
+		}
+		if maxObjects > 0 {
+			data.ObjectsUtilization = types.Float64Value(float64(numObjects) * 100 / float64(maxObjects))
+			if data.ObjectsUtilization.ValueFloat64() >= threshold {
+				data.OverThreshold = types.BoolValue(true)
+			}
+		}
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -2,30 +2,44 @@ package provider
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"math/rand"
+	"math/big"
+	"os"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
+// defaultPurgeConcurrency is the number of buckets purged in parallel by default
+const defaultPurgeConcurrency = 8
+
 const accessKeyBytes = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.ResourceWithConfigure = &UserResource{}
 var _ resource.ResourceWithImportState = &UserResource{}
-var _ resource.ResourceWithImportState = &UserResource{}
+var _ resource.ResourceWithModifyPlan = &UserResource{}
 
 func NewUserResource() resource.Resource {
 	return &UserResource{}
@@ -36,23 +50,50 @@ type UserResource struct {
 }
 
 type UserResourceModel struct {
-	Id                     types.String    `tfsdk:"id"`
-	Username               types.String    `tfsdk:"username"`
-	DisplayName            types.String    `tfsdk:"display_name"`
-	Email                  types.String    `tfsdk:"email"`
-	GenerateS3Credentials  types.Bool      `tfsdk:"generate_s3_credentials"`
-	ExclusiveS3Credentials types.Bool      `tfsdk:"exclusive_s3_credentials"`
-	Caps                   []UserCapModel  `tfsdk:"caps"`
-	OpMask                 types.String    `tfsdk:"op_mask"`
-	MaxBuckets             types.Int64     `tfsdk:"max_buckets"`
-	Suspended              types.Bool      `tfsdk:"suspended"`
-	Tenant                 types.String    `tfsdk:"tenant"`
-	AccessKey              types.String    `tfsdk:"access_key"`
-	SecretKey              types.String    `tfsdk:"secret_key"`
-	PurgeDataOnDelete      types.Bool      `tfsdk:"purge_data_on_delete"`
-	Principal              types.String    `tfsdk:"principal"`
-	UserQuota              *UserQuotaModel `tfsdk:"user_quota"`
-	BucketQuota            *UserQuotaModel `tfsdk:"bucket_quota"`
+	Id                     types.String        `tfsdk:"id"`
+	Username               types.String        `tfsdk:"username"`
+	DisplayName            types.String        `tfsdk:"display_name"`
+	Email                  types.String        `tfsdk:"email"`
+	GenerateS3Credentials  types.Bool          `tfsdk:"generate_s3_credentials"`
+	ExclusiveS3Credentials types.Bool          `tfsdk:"exclusive_s3_credentials"`
+	Caps                   []UserCapModel      `tfsdk:"caps"`
+	OpMask                 types.String        `tfsdk:"op_mask"`
+	MaxBuckets             types.Int64         `tfsdk:"max_buckets"`
+	Suspended              types.Bool          `tfsdk:"suspended"`
+	Tenant                 types.String        `tfsdk:"tenant"`
+	AccessKey              types.String        `tfsdk:"access_key"`
+	SecretKey              types.String        `tfsdk:"secret_key"`
+	PurgeDataOnDelete      types.Bool          `tfsdk:"purge_data_on_delete"`
+	PurgeConcurrency       types.Int64         `tfsdk:"purge_concurrency"`
+	Principal              types.String        `tfsdk:"principal"`
+	UserQuota              *UserQuotaModel     `tfsdk:"user_quota"`
+	BucketQuota            *UserQuotaModel     `tfsdk:"bucket_quota"`
+	CreateDate             types.String        `tfsdk:"create_date"`
+	LastModified           types.String        `tfsdk:"last_modified"`
+	SwiftKeys              []UserSwiftKeyModel `tfsdk:"swift_keys"`
+	Subusers               []UserSubuserModel  `tfsdk:"subusers"`
+	ExtraAttributes        types.Map           `tfsdk:"extra_attributes"`
+	AdoptExisting          types.Bool          `tfsdk:"adopt_existing"`
+	UserInfo               types.Object        `tfsdk:"user_info"`
+	ExternalAuth           types.Bool          `tfsdk:"external_auth"`
+}
+
+// manageS3Credentials reports whether the resource manages a s3 key pair
+func (m *UserResourceModel) manageS3Credentials() bool {
+	if m.ExternalAuth.ValueBool() {
+		return false
+	}
+	return m.GenerateS3Credentials.ValueBool() || m.GenerateS3Credentials.IsNull()
+}
+
+type UserSubuserModel struct {
+	Id          types.String `tfsdk:"id"`
+	Permissions types.String `tfsdk:"permissions"`
+}
+
+type UserSwiftKeyModel struct {
+	User      types.String `tfsdk:"user"`
+	SecretKey types.String `tfsdk:"secret_key"`
 }
 
 type UserCapModel struct {
@@ -93,11 +134,11 @@ func (r *UserResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				},
 			},
 			"display_name": schema.StringAttribute{
-				MarkdownDescription: "Display Name of user",
+				MarkdownDescription: "Display Name of user. Differences in whitespace or unicode normalization to the name stored by RGW are ignored.",
 				Required:            true,
 			},
 			"email": schema.StringAttribute{
-				MarkdownDescription: "The email address associated with the user.",
+				MarkdownDescription: "The email address associated with the user. Differences in case to the address stored by RGW are ignored.",
 				Optional:            true,
 			},
 			"generate_s3_credentials": schema.BoolAttribute{
@@ -105,20 +146,33 @@ func (r *UserResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				MarkdownDescription: "Specify whether to generate S3 Credentials for the user. Set to false to generate swift keys via rgw_subuser.",
 				Optional:            true,
 			},
+			"external_auth": schema.BoolAttribute{
+				MarkdownDescription: "Mark the user as authenticated by an external service like Keystone or LDAP. No keys are generated or tracked for such users and `generate_s3_credentials` is ignored.",
+				Optional:            true,
+			},
 			"exclusive_s3_credentials": schema.BoolAttribute{
 				Description:         "Specify whether other s3 credentials for this user not managed by this ressource should be deleted.",
 				MarkdownDescription: "Specify how to deal with s3 credentials for this user not managed by this resource. Set to `true` to delete all other s3 credentials. Set to `false` to ignore other credentials.",
 				Optional:            true,
 			},
+			"adopt_existing": schema.BoolAttribute{
+				MarkdownDescription: "If a user with the same ID already exists on create, adopt it into the state and apply the configured attributes instead of failing. An existing s3 key pair is reused if `generate_s3_credentials` is set. Useful for bootstrap pipelines that must be re-runnable.",
+				Optional:            true,
+			},
 			"caps": schema.ListNestedAttribute{
 				Optional: true,
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"type": schema.StringAttribute{
-							Required: true,
+							MarkdownDescription: "The cap type, e.g. `users` or `buckets`. Validated at plan time against the types understood by the configured Ceph version and `extra_cap_types` of the provider.",
+							Required:            true,
 						},
 						"perm": schema.StringAttribute{
-							Required: true,
+							MarkdownDescription: "One of `*`, `read`, `write` or `read, write`",
+							Required:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(rgwCapPerms...),
+							},
 						},
 					},
 				},
@@ -158,7 +212,7 @@ func (r *UserResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				},
 			},
 			"access_key": schema.StringAttribute{
-				MarkdownDescription: "The generated access key",
+				MarkdownDescription: "The generated access key. If the key is removed outside of Terraform, a new key pair is planned. Other keys of the user, e.g. of `rgw_user_key`, are never taken over.",
 				Computed:            true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
@@ -173,8 +227,15 @@ func (r *UserResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				},
 			},
 			"purge_data_on_delete": schema.BoolAttribute{
-				MarkdownDescription: "Purge user data on deletion",
+				MarkdownDescription: "Purge user data on deletion. The buckets of the user are purged in parallel before the user is removed.",
+				Optional:            true,
+			},
+			"purge_concurrency": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("Number of buckets purged in parallel if `purge_data_on_delete` is set. Defaults to `%d`.", defaultPurgeConcurrency),
 				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
 			},
 			"principal": schema.StringAttribute{
 				MarkdownDescription: "Computed principal to be used in policies",
@@ -183,6 +244,69 @@ func (r *UserResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"swift_keys": schema.ListNestedAttribute{
+				MarkdownDescription: "Swift keys of the users subusers. Swift quotas are enforced through `user_quota`.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"user": schema.StringAttribute{
+							MarkdownDescription: "The subuser the key belongs to",
+							Computed:            true,
+						},
+						"secret_key": schema.StringAttribute{
+							MarkdownDescription: "The swift secret key",
+							Computed:            true,
+							Sensitive:           true,
+						},
+					},
+				},
+			},
+			"subusers": schema.ListNestedAttribute{
+				MarkdownDescription: "Subusers of the user",
+				Computed:            true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "The subuser ID (`user:subuser`)",
+							Computed:            true,
+						},
+						"permissions": schema.StringAttribute{
+							MarkdownDescription: "The access level of the subuser",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"user_info": schema.ObjectAttribute{
+				MarkdownDescription: userInfoDescription,
+				AttributeTypes:      userInfoAttributeTypes,
+				Computed:            true,
+			},
+			"extra_attributes": schema.MapAttribute{
+				MarkdownDescription: "Additional fields of the user metadata not covered by this resource, written via the metadata api. Values must be JSON encoded, e.g. `jsonencode(\"value\")`. Only the configured fields are managed, removed fields are left untouched.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(stringvalidator.NoneOf(userManagedMetadataFields...)),
+				},
+			},
+			"create_date": schema.StringAttribute{
+				MarkdownDescription: "Creation date of the user as reported by the metadata api. Empty on releases not recording it, null without the `metadata=read` cap.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_modified": schema.StringAttribute{
+				MarkdownDescription: "Last modification time of the user metadata",
+				Computed:            true,
+			},
 			"user_quota": schema.SingleNestedAttribute{
 				MarkdownDescription: "User quota settings",
 				Optional:            true,
@@ -212,7 +336,7 @@ func (r *UserResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				},
 			},
 			"bucket_quota": schema.SingleNestedAttribute{
-				MarkdownDescription: "Bucket quota settings",
+				MarkdownDescription: "Bucket quota settings. Do not combine with `rgw_user_default_bucket_quota` for the same user.",
 				Optional:            true,
 				Attributes: map[string]schema.Attribute{
 					"enabled": schema.BoolAttribute{
@@ -261,9 +385,82 @@ func (r *UserResource) Configure(ctx context.Context, req resource.ConfigureRequ
 	}
 
 	r.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "rgw_user")...)
+}
+
+func (r *UserResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to check without configured provider
+	if r.client == nil {
+		return
+	}
+
+	// check tenant against allowed_tenants, also on destroy
+	resp.Diagnostics.Append(r.client.planTenant(ctx, req, "tenant", func(tenant string) (string, bool) { return tenant, true })...)
+
+	// check username against user_prefix, unless the prefix is prepended anyway
+	if !r.client.PrependPrefix {
+		resp.Diagnostics.Append(r.client.planUserPrefix(ctx, req, "username")...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// plan new credentials if they are requested but the user has none
+	if !req.Plan.Raw.IsNull() {
+		resp.Diagnostics.Append(r.planCredentials(ctx, req, resp)...)
+	}
+
+	// refuse to destroy protected users or to modify their keys
+	resp.Diagnostics.Append(r.client.planProtectedUser(ctx, req, resp)...)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	// enforce the email convention of the provider
+	resp.Diagnostics.Append(r.client.planUserEmail(ctx, req)...)
+
+	// writing extra attributes needs write access to the metadata api
+	var extraAttributes types.Map
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("extra_attributes"), &extraAttributes)...)
+	if !extraAttributes.IsNull() {
+		resp.Diagnostics.Append(r.client.checkRequiredCaps(ctx, "rgw_user.extra_attributes")...)
+	}
+
+	// purging buckets on delete needs write access to the buckets api
+	var purgeData types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("purge_data_on_delete"), &purgeData)...)
+	if purgeData.ValueBool() {
+		resp.Diagnostics.Append(r.client.checkRequiredCaps(ctx, "rgw_user.purge_data_on_delete")...)
+	}
+
+	// check cap types against the types understood by rgw
+	var caps types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("caps"), &caps)...)
+	if resp.Diagnostics.HasError() || caps.IsNull() || caps.IsUnknown() {
+		return
+	}
+
+	var capModels []UserCapModel
+	resp.Diagnostics.Append(caps.ElementsAs(ctx, &capModels, false)...)
+	for i, c := range capModels {
+		if c.Type.IsUnknown() || c.Type.IsNull() {
+			continue
+		}
+		if err := r.client.validateCapType(c.Type.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("caps").AtListIndex(i).AtName("type"), "invalid cap type", err.Error())
+		}
+	}
 }
 
 func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("create user")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Read Terraform plan data into the model
 	var data *UserResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -278,27 +475,17 @@ func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, r
 		OpMask:      data.OpMask.ValueString(),
 	}
 	if data.Tenant.IsNull() {
-		rgwUser.ID = data.Username.ValueString()
+		rgwUser.ID = r.client.prefixedUserId(data.Username.ValueString())
 	} else {
-		rgwUser.ID = fmt.Sprintf("%s$%s", data.Tenant.ValueString(), data.Username.ValueString())
+		rgwUser.ID = fmt.Sprintf("%s$%s", data.Tenant.ValueString(), r.client.prefixedUserId(data.Username.ValueString()))
 	}
 	generateKey := false
-	if data.GenerateS3Credentials.ValueBool() || data.GenerateS3Credentials.IsNull() {
+	if data.manageS3Credentials() {
 		generateKey = true
 		rgwUser.KeyType = "s3"
 	}
 	rgwUser.GenerateKey = &generateKey
 
-	if len(data.Caps) > 0 {
-		rgwUser.Caps = make([]admin.UserCapSpec, len(data.Caps))
-		for i, c := range data.Caps {
-			rgwUser.Caps[i] = admin.UserCapSpec{
-				Type: c.Type.ValueString(),
-				Perm: c.Type.ValueString(),
-			}
-		}
-	}
-
 	maxBuckets := int(data.MaxBuckets.ValueInt64())
 	rgwUser.MaxBuckets = &maxBuckets
 
@@ -308,26 +495,52 @@ func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, r
 	}
 	rgwUser.Suspended = &suspended
 
-	// create user
-	createdUser, err := r.client.Admin.CreateUser(ctx, rgwUser)
+	// fail early with a precise error if the user already exists
+	exists, err := r.client.userExists(ctx, rgwUser.ID)
 	if err != nil {
-		resp.Diagnostics.AddError("could not create user", err.Error())
+		resp.Diagnostics.AddError("could not check if user exists", err.Error())
+		return
+	}
+	if exists && !data.AdoptExisting.ValueBool() {
+		// nothing proves the user was created by this provider, e.g. by an
+		// interrupted apply, so never take it over without being asked to
+		resp.Diagnostics.AddError("user already exists",
+			fmt.Sprintf("The user '%s' already exists. Consider importing it with: terraform import <address> '%s' or setting adopt_existing.", rgwUser.ID, rgwUser.ID))
 		return
 	}
 
+	var createdUser admin.User
+	if exists {
+		// adopt existing user
+		tflog.Info(ctx, fmt.Sprintf("adopt existing user %s", rgwUser.ID))
+		createdUser, err = r.adoptUser(ctx, rgwUser)
+		if err != nil {
+			resp.Diagnostics.AddError("could not adopt user", err.Error())
+			return
+		}
+	} else {
+		// create user
+		createdUser, err = r.client.Admin.CreateUser(ctx, rgwUser)
+		if err != nil {
+			resp.Diagnostics.AddError("could not create user", err.Error())
+			return
+		}
+	}
+
 	// set resource id - use the constructed ID to ensure consistency
 	data.Id = types.StringValue(rgwUser.ID)
 
 	// set principal ARN
 	if data.Tenant.IsNull() {
-		data.Principal = types.StringValue(fmt.Sprintf("arn:aws:iam:::user/%s", data.Username.ValueString()))
+		data.Principal = types.StringValue(fmt.Sprintf("arn:aws:iam:::user/%s", r.client.prefixedUserId(data.Username.ValueString())))
 	} else {
-		data.Principal = types.StringValue(fmt.Sprintf("arn:aws:iam::%s:user/%s", data.Tenant.ValueString(), data.Username.ValueString()))
+		data.Principal = types.StringValue(fmt.Sprintf("arn:aws:iam::%s:user/%s", data.Tenant.ValueString(), r.client.prefixedUserId(data.Username.ValueString())))
 	}
 
 	// set access and secret key
 	if generateKey {
-		if len(createdUser.Keys) == 1 {
+		// an adopted user may already own several key pairs, the first one is used
+		if len(createdUser.Keys) == 1 || (exists && len(createdUser.Keys) > 1) {
 			data.AccessKey = types.StringValue(createdUser.Keys[0].AccessKey)
 			data.SecretKey = types.StringValue(createdUser.Keys[0].SecretKey)
 		} else {
@@ -339,24 +552,52 @@ func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, r
 		data.SecretKey = types.StringNull()
 	}
 
+	// grant caps, an adopted user may already have some of them
+	err = r.client.updateCaps(ctx, rgwUser.ID, createdUser.Caps, capsFromModel(data.Caps))
+	if err != nil {
+		resp.Diagnostics.AddError("could not set user caps", err.Error())
+		return
+	}
+
+	// set swift keys and subusers
+	data.SwiftKeys = swiftKeysFromApi(createdUser.SwiftKeys)
+	data.Subusers = subusersFromApi(createdUser.Subusers)
+
 	// Set user quota if configured
 	if data.UserQuota != nil {
-		err = r.setQuota(ctx, rgwUser.ID, "user", data.UserQuota)
+		err = r.client.setQuota(ctx, rgwUser.ID, "user", data.UserQuota)
 		if err != nil {
 			resp.Diagnostics.AddError("could not set user quota", err.Error())
 			return
 		}
+		resp.Diagnostics.Append(r.client.verifyUserQuota(ctx, rgwUser.ID, "user", data.UserQuota)...)
 	}
 
 	// Set bucket quota if configured
 	if data.BucketQuota != nil {
-		err = r.setQuota(ctx, rgwUser.ID, "bucket", data.BucketQuota)
+		err = r.client.setQuota(ctx, rgwUser.ID, "bucket", data.BucketQuota)
 		if err != nil {
 			resp.Diagnostics.AddError("could not set bucket quota", err.Error())
 			return
 		}
+		resp.Diagnostics.Append(r.client.verifyUserQuota(ctx, rgwUser.ID, "bucket", data.BucketQuota)...)
 	}
 
+	// Set extra attributes if configured
+	if !data.ExtraAttributes.IsNull() {
+		err = r.setExtraAttributes(ctx, rgwUser.ID, data.ExtraAttributes)
+		if err != nil {
+			resp.Diagnostics.AddError("could not set extra attributes", err.Error())
+			return
+		}
+	}
+
+	// set metadata timestamps
+	resp.Diagnostics.Append(r.readMetadata(ctx, data)...)
+
+	// set user info, quotas and keys may have changed since the user was written
+	resp.Diagnostics.Append(r.readUserInfo(ctx, data)...)
+
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -409,24 +650,27 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	// Use the expected ID from state to handle cases where API returns different format
 	idToSplit := expectedId
 	splittedId := strings.SplitN(idToSplit, "$", 2)
+	username := idToSplit
 	if len(splittedId) == 2 {
-		data.Username = types.StringValue(splittedId[1])
+		username = splittedId[1]
 		data.Tenant = types.StringValue(splittedId[0])
 	} else {
-		data.Username = types.StringValue(idToSplit)
 		data.Tenant = types.StringNull()
 	}
+	// keep the configured username if prepend_prefix maps it to the user
+	if r.client.prefixedUserId(data.Username.ValueString()) != username {
+		data.Username = types.StringValue(r.client.unprefixedUserId(username))
+	}
 
-	// update display name
-	data.DisplayName = types.StringValue(user.DisplayName)
+	// update display name, keep the configured spelling if it only differs in whitespace or unicode normalization
+	if normalizeDisplayName(user.DisplayName) != normalizeDisplayName(data.DisplayName.ValueString()) {
+		data.DisplayName = types.StringValue(user.DisplayName)
+	}
 
-	// update email
-	if len(user.Email) > 0 || !data.Email.IsNull() {
+	// update email, keep the configured spelling if it only differs in case
+	if (len(user.Email) > 0 || !data.Email.IsNull()) && normalizeEmail(user.Email) != normalizeEmail(data.Email.ValueString()) {
 		data.Email = types.StringValue(user.Email)
 	}
-	if user.Email == "" && len(data.Email.ValueString()) > 0 {
-		data.Email = types.StringValue("")
-	}
 
 	// update caps
 	if len(user.Caps) > 0 {
@@ -453,38 +697,24 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		}
 	}
 
-	// update credentials
-	if data.GenerateS3Credentials.ValueBool() || data.GenerateS3Credentials.IsNull() {
-		found := false
-		if data.AccessKey.IsNull() || data.AccessKey.IsUnknown() {
-			resp.Diagnostics.Append(resp.Private.SetKey(ctx, "mark_unknown_access_key", []byte("1"))...)
-		} else {
-			for _, k := range user.Keys {
-				if k.AccessKey == data.AccessKey.ValueString() {
-					found = true
-					data.SecretKey = types.StringValue(k.SecretKey)
-					resp.Diagnostics.Append(resp.Private.SetKey(ctx, "mark_unknown_access_key", []byte("0"))...)
-					resp.Diagnostics.Append(resp.Private.SetKey(ctx, "mark_unknown_secret_key", []byte("0"))...)
-					break
-				}
-			}
-		}
-		if !found {
-			resp.Diagnostics.Append(resp.Private.SetKey(ctx, "mark_unknown_secret_key", []byte("1"))...)
-		}
-		if len(user.Keys) > 1 || (len(user.Keys) == 1 && !found) {
-			data.ExclusiveS3Credentials = types.BoolValue(false)
-		}
+	// update credentials. A key rotated or removed outside of terraform is
+	// dropped from the state, so ModifyPlan plans a new one. Other keys of the
+	// user may belong to rgw_user_key resources and are never taken over, nor
+	// do they change exclusive_s3_credentials.
+	if data.manageS3Credentials() {
+		data.AccessKey, data.SecretKey = s3CredentialsFromApi(user.Keys, data.AccessKey.ValueString())
 	} else {
-		resp.Diagnostics.Append(resp.Private.SetKey(ctx, "mark_unknown_access_key", []byte("0"))...)
-		resp.Diagnostics.Append(resp.Private.SetKey(ctx, "mark_unknown_secret_key", []byte("0"))...)
 		data.AccessKey = types.StringNull()
 		data.SecretKey = types.StringNull()
 	}
 
+	// update swift keys and subusers
+	data.SwiftKeys = swiftKeysFromApi(user.SwiftKeys)
+	data.Subusers = subusersFromApi(user.Subusers)
+
 	// Read user quota if it was configured
 	if data.UserQuota != nil {
-		userQuota, err := r.getQuota(ctx, data.Id.ValueString(), "user")
+		userQuota, err := r.client.getQuota(ctx, data.Id.ValueString(), "user")
 		if err != nil {
 			resp.Diagnostics.AddError("could not get user quota", err.Error())
 			return
@@ -494,7 +724,7 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 
 	// Read bucket quota if it was configured
 	if data.BucketQuota != nil {
-		bucketQuota, err := r.getQuota(ctx, data.Id.ValueString(), "bucket")
+		bucketQuota, err := r.client.getQuota(ctx, data.Id.ValueString(), "bucket")
 		if err != nil {
 			resp.Diagnostics.AddError("could not get bucket quota", err.Error())
 			return
@@ -502,11 +732,38 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		data.BucketQuota = bucketQuota
 	}
 
+	// Read extra attributes if they were configured
+	if !data.ExtraAttributes.IsNull() {
+		extraAttributes, err := r.getExtraAttributes(ctx, data.Id.ValueString(), data.ExtraAttributes)
+		if err != nil {
+			resp.Diagnostics.AddError("could not get extra attributes", err.Error())
+			return
+		}
+		data.ExtraAttributes = extraAttributes
+	}
+
+	// update metadata timestamps
+	resp.Diagnostics.Append(r.readMetadata(ctx, data)...)
+
+	// update user info
+	userInfo, diags := userInfoFromApi(user)
+	resp.Diagnostics.Append(diags...)
+	data.UserInfo = userInfo
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	// report drift in detail if requested
+	resp.Diagnostics.Append(r.client.warnDrift(ctx, "rgw_user", req.State, resp.State)...)
 }
 
 func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("update user")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Read Terraform plan data into the model
 	var data *UserResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -514,80 +771,100 @@ func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
+	// Read prior state to send only changed fields, full modify calls could
+	// clobber concurrent changes to fields not managed by terraform
+	var state *UserResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// instantiate api request user struct
 	update := admin.User{
-		ID:          data.Id.ValueString(),
-		DisplayName: data.DisplayName.ValueString(),
-		Email:       data.Email.ValueString(),
-		OpMask:      data.OpMask.ValueString(),
+		ID: data.Id.ValueString(),
 	}
+	changed := false
 
 	// do not generate key here
 	generate := false
 	update.GenerateKey = &generate
 
-	// set user caps
-	if len(data.Caps) > 0 {
-		update.Caps = make([]admin.UserCapSpec, len(data.Caps))
-		for i, c := range data.Caps {
-			update.Caps[i] = admin.UserCapSpec{
-				Type: c.Type.ValueString(),
-				Perm: c.Type.ValueString(),
-			}
-		}
+	if !data.DisplayName.Equal(state.DisplayName) {
+		update.DisplayName = data.DisplayName.ValueString()
+		changed = true
+	}
+	if !data.Email.Equal(state.Email) {
+		update.Email = data.Email.ValueString()
+		changed = true
+	}
+	if !data.OpMask.Equal(state.OpMask) {
+		update.OpMask = data.OpMask.ValueString()
+		changed = true
 	}
 
 	// set max_buckets
-	maxBuckets := int(data.MaxBuckets.ValueInt64())
-	update.MaxBuckets = &maxBuckets
+	if !data.MaxBuckets.Equal(state.MaxBuckets) {
+		maxBuckets := int(data.MaxBuckets.ValueInt64())
+		update.MaxBuckets = &maxBuckets
+		changed = true
+	}
 
 	// set suspended
-	suspended := 0
-	if data.Suspended.ValueBool() {
-		suspended = 1
+	if !data.Suspended.Equal(state.Suspended) {
+		suspended := 0
+		if data.Suspended.ValueBool() {
+			suspended = 1
+		}
+		update.Suspended = &suspended
+		changed = true
 	}
-	update.Suspended = &suspended
 
-	// modify user
-	user, err := r.client.Admin.ModifyUser(ctx, update)
-	if err != nil {
-		resp.Diagnostics.AddError("could not modify user", err.Error())
-		return
+	// modify user, only get it if no user field changed
+	var user admin.User
+	var err error
+	if changed {
+		user, err = r.client.Admin.ModifyUser(ctx, update)
+		if err != nil {
+			resp.Diagnostics.AddError("could not modify user", err.Error())
+			return
+		}
+	} else {
+		user, err = r.client.Admin.GetUser(ctx, admin.User{ID: update.ID})
+		if err != nil {
+			resp.Diagnostics.AddError("could not get user", err.Error())
+			return
+		}
 	}
 
-	// Preserve existing S3 credentials during updates - only regenerate if explicitly requested
-	// Read existing state to get current credentials
-	var state *UserResourceModel
-	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
-	if resp.Diagnostics.HasError() {
+	// add and remove only the changed caps, replacing the whole set would
+	// briefly revoke caps the user still needs
+	err = r.client.updateCaps(ctx, update.ID, capsFromModel(state.Caps), capsFromModel(data.Caps))
+	if err != nil {
+		resp.Diagnostics.AddError("could not update user caps", err.Error())
 		return
 	}
 
+	// Preserve existing S3 credentials during updates - only regenerate if explicitly requested
 	// If we have existing credentials in state, preserve them
-	if !state.AccessKey.IsNull() && !state.SecretKey.IsNull() {
+	if !data.manageS3Credentials() {
+		data.AccessKey = types.StringNull()
+		data.SecretKey = types.StringNull()
+		data.Principal = state.Principal
+	} else if !state.AccessKey.IsNull() && !state.SecretKey.IsNull() {
 		data.AccessKey = state.AccessKey
 		data.SecretKey = state.SecretKey
 		data.Principal = state.Principal // Preserve the principal ARN as well
-	} else if len(user.Keys) > 0 {
-		// If no state credentials but API has keys, use the first one
-		data.AccessKey = types.StringValue(user.Keys[0].AccessKey)
-		data.SecretKey = types.StringValue(user.Keys[0].SecretKey)
-		// Set principal ARN
-		if data.Tenant.IsNull() {
-			data.Principal = types.StringValue(fmt.Sprintf("arn:aws:iam:::user/%s", data.Username.ValueString()))
-		} else {
-			data.Principal = types.StringValue(fmt.Sprintf("arn:aws:iam::%s:user/%s", data.Tenant.ValueString(), data.Username.ValueString()))
-		}
 	} else {
-		// No existing credentials and no API keys - this shouldn't happen in normal updates
-		// but if it does, generate new credentials
-		if data.GenerateS3Credentials.ValueBool() || data.GenerateS3Credentials.IsNull() {
+		// the key was dropped on refresh, generate the new key planned by
+		// ModifyPlan instead of taking over another key of the user
+		if data.manageS3Credentials() {
 			// Generate new access key
-			a := make([]byte, 20)
-			for i := range a {
-				a[i] = accessKeyBytes[rand.Intn(len(accessKeyBytes))]
+			accessKey, err := generateAccessKey()
+			if err != nil {
+				resp.Diagnostics.AddError("could not generate access key", err.Error())
+				return
 			}
-			data.AccessKey = types.StringValue(string(a))
+			data.AccessKey = types.StringValue(accessKey)
 
 			generate := true
 			keys, err := r.client.Admin.CreateKey(ctx, admin.UserKeySpec{
@@ -612,36 +889,63 @@ func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, r
 
 			// Set principal ARN
 			if data.Tenant.IsNull() {
-				data.Principal = types.StringValue(fmt.Sprintf("arn:aws:iam:::user/%s", data.Username.ValueString()))
+				data.Principal = types.StringValue(fmt.Sprintf("arn:aws:iam:::user/%s", r.client.prefixedUserId(data.Username.ValueString())))
 			} else {
-				data.Principal = types.StringValue(fmt.Sprintf("arn:aws:iam::%s:user/%s", data.Tenant.ValueString(), data.Username.ValueString()))
+				data.Principal = types.StringValue(fmt.Sprintf("arn:aws:iam::%s:user/%s", data.Tenant.ValueString(), r.client.prefixedUserId(data.Username.ValueString())))
 			}
 		}
 	}
 
+	// update swift keys and subusers
+	data.SwiftKeys = swiftKeysFromApi(user.SwiftKeys)
+	data.Subusers = subusersFromApi(user.Subusers)
+
 	// Update user quota if configured
 	if data.UserQuota != nil {
-		err = r.setQuota(ctx, data.Id.ValueString(), "user", data.UserQuota)
+		err = r.client.setQuota(ctx, data.Id.ValueString(), "user", data.UserQuota)
 		if err != nil {
 			resp.Diagnostics.AddError("could not set user quota", err.Error())
 			return
 		}
+		resp.Diagnostics.Append(r.client.verifyUserQuota(ctx, data.Id.ValueString(), "user", data.UserQuota)...)
 	}
 
 	// Update bucket quota if configured
 	if data.BucketQuota != nil {
-		err = r.setQuota(ctx, data.Id.ValueString(), "bucket", data.BucketQuota)
+		err = r.client.setQuota(ctx, data.Id.ValueString(), "bucket", data.BucketQuota)
 		if err != nil {
 			resp.Diagnostics.AddError("could not set bucket quota", err.Error())
 			return
 		}
+		resp.Diagnostics.Append(r.client.verifyUserQuota(ctx, data.Id.ValueString(), "bucket", data.BucketQuota)...)
+	}
+
+	// Update extra attributes if configured
+	if !data.ExtraAttributes.IsNull() {
+		err = r.setExtraAttributes(ctx, data.Id.ValueString(), data.ExtraAttributes)
+		if err != nil {
+			resp.Diagnostics.AddError("could not set extra attributes", err.Error())
+			return
+		}
 	}
 
+	// update metadata timestamps
+	resp.Diagnostics.Append(r.readMetadata(ctx, data)...)
+
+	// set user info, quotas and keys may have changed since the user was written
+	resp.Diagnostics.Append(r.readUserInfo(ctx, data)...)
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// refuse to modify anything in read only mode
+	resp.Diagnostics.Append(r.client.checkWritable("delete user")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Read Terraform prior state data into the model
 	var data *UserResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -649,11 +953,24 @@ func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
-	// send delete request to api
+	// purge the buckets first, a single purge request times out for users
+	// owning thousands of buckets
 	purgeData := 0
 	if data.PurgeDataOnDelete.ValueBool() {
 		purgeData = 1
+
+		concurrency := defaultPurgeConcurrency
+		if !data.PurgeConcurrency.IsNull() {
+			concurrency = int(data.PurgeConcurrency.ValueInt64())
+		}
+		err := r.purgeBuckets(ctx, data.Id.ValueString(), concurrency)
+		if err != nil {
+			resp.Diagnostics.AddError("could not purge buckets of user", err.Error())
+			return
+		}
 	}
+
+	// send delete request to api
 	err := r.client.Admin.RemoveUser(ctx, admin.User{
 		ID:        data.Id.ValueString(),
 		PurgeData: &purgeData,
@@ -664,6 +981,58 @@ func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 	}
 }
 
+// purgeBuckets removes all buckets of the user including their objects,
+// purging up to concurrency buckets at a time
+func (r *UserResource) purgeBuckets(ctx context.Context, userId string, concurrency int) error {
+	buckets, err := r.client.listUserBuckets(ctx, userId)
+	if errors.Is(err, admin.ErrNoSuchUser) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(buckets) == 0 {
+		return nil
+	}
+	tflog.Info(ctx, fmt.Sprintf("purge %d buckets of user %s, %d in parallel", len(buckets), userId, concurrency))
+
+	var (
+		wg     sync.WaitGroup
+		lock   sync.Mutex
+		failed []string
+		done   int
+	)
+	purgeObjects := true
+	slots := make(chan struct{}, concurrency)
+	for _, bucket := range buckets {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(bucket string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			err := r.client.Admin.RemoveBucket(ctx, admin.Bucket{Bucket: bucket, PurgeObject: &purgeObjects})
+
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil && !errors.Is(err, admin.ErrNoSuchBucket) {
+				failed = append(failed, fmt.Sprintf("%s: %s", bucket, err))
+				return
+			}
+			done++
+			tflog.Info(ctx, fmt.Sprintf("purged bucket %s of user %s (%d/%d)", bucket, userId, done, len(buckets)))
+		}(bucket)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("could not purge %d of %d buckets: %s", len(failed), len(buckets), strings.Join(failed, "; "))
+	}
+
+	return nil
+}
+
 /*
 	type boolEnforceDefaultValueModifier struct {
 		Default bool
@@ -684,46 +1053,60 @@ func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		}
 	}
 */
-type stringPrivateUnknownModifier struct {
-	Suffix string
-}
-
-func (m stringPrivateUnknownModifier) Description(ctx context.Context) string {
-	return fmt.Sprintf("Set field to unknown if private provider data key 'mark_unknown_%s' contains '1'", m.Suffix)
-}
-
-func (m stringPrivateUnknownModifier) MarkdownDescription(ctx context.Context) string {
-	return fmt.Sprintf("Set field to unknown if private provider data key 'mark_unknown_%s' contains '1'", m.Suffix)
-}
+// planCredentials marks the s3 credentials as unknown if they are requested
+// but the user has no key pair, e.g. because it was removed outside of
+// terraform. Update then generates a new key pair.
+func (r *UserResource) planCredentials(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if req.State.Raw.IsNull() {
+		return diags
+	}
 
-func (m stringPrivateUnknownModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
-	data, diag := req.Private.GetKey(ctx, fmt.Sprintf("mark_unknown_%s", m.Suffix))
-	resp.Diagnostics.Append(diag...)
+	var plan UserResourceModel
+	var accessKey types.String
+	diags.Append(req.Plan.GetAttribute(ctx, path.Root("generate_s3_credentials"), &plan.GenerateS3Credentials)...)
+	diags.Append(req.Plan.GetAttribute(ctx, path.Root("external_auth"), &plan.ExternalAuth)...)
+	diags.Append(req.State.GetAttribute(ctx, path.Root("access_key"), &accessKey)...)
+	if diags.HasError() {
+		return diags
+	}
 
-	if data != nil {
-		if string(data) == "1" {
-			resp.PlanValue = types.StringUnknown()
-		}
+	if plan.manageS3Credentials() && accessKey.IsNull() {
+		diags.Append(resp.Plan.SetAttribute(ctx, path.Root("access_key"), types.StringUnknown())...)
+		diags.Append(resp.Plan.SetAttribute(ctx, path.Root("secret_key"), types.StringUnknown())...)
 	}
+
+	return diags
 }
 
 func (r *UserResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	// The import ID should be the full user ID (tenant$username or just username)
+	// or the path of a metadata dump prefixed with 'file:'
 	userId := req.ID
 
-	// Use the ID as-is for the resource ID
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	var user admin.User
+	var err error
+	if strings.HasPrefix(req.ID, "file:") {
+		// Read user details from a dump of `radosgw-admin metadata get user:<id>`
+		user, err = userFromMetadataFile(strings.TrimPrefix(req.ID, "file:"))
+		if err != nil {
+			resp.Diagnostics.AddError("could not read user metadata dump for import", err.Error())
+			return
+		}
+		userId = user.ID
+	} else {
+		// Fetch user details to import existing S3 credentials
+		userId = r.client.prefixedUserId(userId)
+		user, err = r.client.Admin.GetUser(ctx, admin.User{ID: userId})
+		if err != nil {
+			resp.Diagnostics.AddError("could not get user for import", err.Error())
+			return
+		}
+	}
 
-	// Also set the ID in the response state for immediate use
+	// Set the ID in the response state for immediate use
 	resp.State.SetAttribute(ctx, path.Root("id"), userId)
 
-	// Fetch user details to import existing S3 credentials
-	user, err := r.client.Admin.GetUser(ctx, admin.User{ID: userId})
-	if err != nil {
-		resp.Diagnostics.AddError("could not get user for import", err.Error())
-		return
-	}
-
 	// Import user attributes
 	resp.State.SetAttribute(ctx, path.Root("op_mask"), user.OpMask)
 
@@ -745,55 +1128,298 @@ func (r *UserResource) ImportState(ctx context.Context, req resource.ImportState
 			resp.State.SetAttribute(ctx, path.Root("exclusive_s3_credentials"), false)
 		}
 	}
+
+	// Import existing swift keys and subusers, so they are known from the first plan on
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("swift_keys"), swiftKeysFromApi(user.SwiftKeys))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("subusers"), subusersFromApi(user.Subusers))...)
 }
 
-// setQuota sets user or bucket quota
-func (r *UserResource) setQuota(ctx context.Context, userId string, quotaType string, quota *UserQuotaModel) error {
-	enabled := quota.Enabled.ValueBool()
-	maxSizeKb := int(quota.MaxSizeKb.ValueInt64())
-	maxObjects := quota.MaxObjects.ValueInt64()
+// s3CredentialsFromApi returns the key pair with the access key from the
+// keys of a user, or null values if the user doesn't have it anymore
+func s3CredentialsFromApi(keys []admin.UserKeySpec, accessKey string) (types.String, types.String) {
+	for _, k := range keys {
+		if accessKey != "" && k.AccessKey == accessKey {
+			return types.StringValue(k.AccessKey), types.StringValue(k.SecretKey)
+		}
+	}
+	return types.StringNull(), types.StringNull()
+}
 
-	quotaSpec := admin.QuotaSpec{
-		UID:        userId,
-		QuotaType:  quotaType,
-		Enabled:    &enabled,
-		MaxSizeKb:  &maxSizeKb,
-		MaxObjects: &maxObjects,
+// generateAccessKey returns a random access key in the format generated by rgw
+func generateAccessKey() (string, error) {
+	a := make([]byte, 20)
+	for i := range a {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(accessKeyBytes))))
+		if err != nil {
+			return "", err
+		}
+		a[i] = accessKeyBytes[n.Int64()]
 	}
+	return string(a), nil
+}
 
-	return r.client.Admin.SetUserQuota(ctx, quotaSpec)
+// swiftKeysFromApi converts the swift keys of an api user
+func swiftKeysFromApi(keys []admin.SwiftKeySpec) []UserSwiftKeyModel {
+	swiftKeys := make([]UserSwiftKeyModel, len(keys))
+	for i, k := range keys {
+		swiftKeys[i].User = types.StringValue(k.User)
+		swiftKeys[i].SecretKey = types.StringValue(k.SecretKey)
+	}
+	return swiftKeys
 }
 
-// getQuota gets user or bucket quota
-func (r *UserResource) getQuota(ctx context.Context, userId string, quotaType string) (*UserQuotaModel, error) {
-	quotaSpec := admin.QuotaSpec{
-		UID:       userId,
-		QuotaType: quotaType,
+// subusersFromApi converts the subusers of an api user
+func subusersFromApi(subusers []admin.SubuserSpec) []UserSubuserModel {
+	models := make([]UserSubuserModel, len(subusers))
+	for i, s := range subusers {
+		models[i].Id = types.StringValue(s.Name)
+		models[i].Permissions = types.StringValue(string(s.Access))
 	}
+	return models
+}
+
+// userManagedMetadataFields are user metadata fields managed by typed attributes
+var userManagedMetadataFields = []string{
+	"user_id", "display_name", "email", "keys", "swift_keys", "subusers", "caps",
+	"suspended", "max_buckets", "op_mask", "user_quota", "bucket_quota", "type",
+}
+
+// capPermRead and capPermWrite are the permission bits of a cap
+const (
+	capPermRead = 1 << iota
+	capPermWrite
+)
 
-	quota, err := r.client.Admin.GetUserQuota(ctx, quotaSpec)
+// capPermBits converts a cap permission like "read, write" or "*" into bits
+func capPermBits(perm string) int {
+	bits := 0
+	for _, p := range strings.Split(perm, ",") {
+		switch strings.TrimSpace(p) {
+		case "*":
+			bits |= capPermRead | capPermWrite
+		case "read":
+			bits |= capPermRead
+		case "write":
+			bits |= capPermWrite
+		}
+	}
+	return bits
+}
+
+// capPermString converts permission bits into a cap permission
+func capPermString(bits int) string {
+	switch bits {
+	case capPermRead:
+		return "read"
+	case capPermWrite:
+		return "write"
+	default:
+		return "read, write"
+	}
+}
+
+// capsFromModel converts the configured caps into api caps
+func capsFromModel(caps []UserCapModel) []admin.UserCapSpec {
+	result := make([]admin.UserCapSpec, len(caps))
+	for i, c := range caps {
+		result[i] = admin.UserCapSpec{Type: c.Type.ValueString(), Perm: c.Perm.ValueString()}
+	}
+	return result
+}
+
+// updateCaps changes the caps of a user from current to planned with targeted
+// add and remove calls. Permissions are added before others are removed, so
+// the user never loses a permission it keeps.
+func (c *RgwClient) updateCaps(ctx context.Context, userId string, current []admin.UserCapSpec, planned []admin.UserCapSpec) error {
+	currentBits := map[string]int{}
+	for _, spec := range current {
+		currentBits[spec.Type] |= capPermBits(spec.Perm)
+	}
+	plannedBits := map[string]int{}
+	for _, spec := range planned {
+		plannedBits[spec.Type] |= capPermBits(spec.Perm)
+	}
+
+	var add, remove []string
+	for capType, bits := range plannedBits {
+		if missing := bits &^ currentBits[capType]; missing != 0 {
+			add = append(add, fmt.Sprintf("%s=%s", capType, capPermString(missing)))
+		}
+	}
+	for capType, bits := range currentBits {
+		if obsolete := bits &^ plannedBits[capType]; obsolete != 0 {
+			remove = append(remove, fmt.Sprintf("%s=%s", capType, capPermString(obsolete)))
+		}
+	}
+	sort.Strings(add)
+	sort.Strings(remove)
+
+	if len(add) > 0 {
+		tflog.Info(ctx, fmt.Sprintf("add caps %s to user %s", strings.Join(add, ";"), userId))
+		if _, err := c.Admin.AddUserCap(ctx, userId, strings.Join(add, ";")); err != nil {
+			return err
+		}
+	}
+	if len(remove) > 0 {
+		tflog.Info(ctx, fmt.Sprintf("remove caps %s from user %s", strings.Join(remove, ";"), userId))
+		if _, err := c.Admin.RemoveUserCap(ctx, userId, strings.Join(remove, ";")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setExtraAttributes writes the given fields into the user metadata
+func (r *UserResource) setExtraAttributes(ctx context.Context, userId string, extraAttributes types.Map) error {
+	meta, err := r.client.getMetadata(ctx, "user", userId)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	model := &UserQuotaModel{}
-	if quota.Enabled != nil {
-		model.Enabled = types.BoolValue(*quota.Enabled)
-	} else {
-		model.Enabled = types.BoolValue(false)
+	for k, v := range extraAttributes.Elements() {
+		value, ok := v.(types.String)
+		if !ok {
+			return fmt.Errorf("unexpected value type %T of extra attribute '%s'", v, k)
+		}
+		if !json.Valid([]byte(value.ValueString())) {
+			return fmt.Errorf("value of extra attribute '%s' is not valid JSON, use jsonencode()", k)
+		}
+		meta.Data[k] = json.RawMessage(value.ValueString())
 	}
 
-	if quota.MaxSizeKb != nil {
-		model.MaxSizeKb = types.Int64Value(int64(*quota.MaxSizeKb))
-	} else {
-		model.MaxSizeKb = types.Int64Value(-1)
+	return r.client.putMetadata(ctx, "user", userId, meta)
+}
+
+// getExtraAttributes reads the fields managed as extra attributes from the user metadata
+func (r *UserResource) getExtraAttributes(ctx context.Context, userId string, extraAttributes types.Map) (types.Map, error) {
+	meta, err := r.client.getMetadata(ctx, "user", userId)
+	if err != nil {
+		return types.MapNull(types.StringType), err
 	}
 
-	if quota.MaxObjects != nil {
-		model.MaxObjects = types.Int64Value(*quota.MaxObjects)
-	} else {
-		model.MaxObjects = types.Int64Value(-1)
+	values := map[string]attr.Value{}
+	for k, v := range extraAttributes.Elements() {
+		raw, ok := meta.Data[k]
+		if !ok {
+			// field was removed, let terraform plan to write it again
+			continue
+		}
+
+		// keep the configured encoding if the values are equal
+		if value, ok := v.(types.String); ok {
+			configured, err := canonicalJSON([]byte(value.ValueString()))
+			if err == nil {
+				current, err := canonicalJSON(raw)
+				if err == nil && configured == current {
+					values[k] = value
+					continue
+				}
+			}
+		}
+		values[k] = types.StringValue(string(raw))
+	}
+
+	m, diags := types.MapValue(types.StringType, values)
+	if diags.HasError() {
+		return types.MapNull(types.StringType), fmt.Errorf("could not build extra attributes")
+	}
+	return m, nil
+}
+
+// userFromMetadataFile reads a user from a dump of `radosgw-admin metadata get user:<id>`
+func userFromMetadataFile(file string) (admin.User, error) {
+	var entry struct {
+		Key  string     `json:"key"`
+		Data admin.User `json:"data"`
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return entry.Data, err
+	}
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return entry.Data, fmt.Errorf("could not decode %s: %w", file, err)
+	}
+	if entry.Data.ID == "" {
+		return entry.Data, fmt.Errorf("%s is not a user metadata entry", file)
+	}
+
+	return entry.Data, nil
+}
+
+// readUserInfo gets the user and sets user_info
+func (r *UserResource) readUserInfo(ctx context.Context, data *UserResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	user, err := r.client.Admin.GetUser(ctx, admin.User{ID: data.Id.ValueString()})
+	if err != nil {
+		diags.AddError("could not get user", err.Error())
+		return diags
+	}
+
+	data.UserInfo, diags = userInfoFromApi(user)
+	return diags
+}
+
+// readMetadata sets create_date and last_modified from the metadata api
+func (r *UserResource) readMetadata(ctx context.Context, data *UserResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// the timestamps are informational, so missing metadata caps must not fail
+	// refreshes or orphan a just created user
+	meta, err := r.client.getUserMetadata(ctx, data.Id.ValueString())
+	if err != nil {
+		denied := errors.Is(err, admin.ErrAccessDenied)
+		if denied || data.CreateDate.IsUnknown() {
+			data.CreateDate = types.StringNull()
+		}
+		if denied || data.LastModified.IsUnknown() {
+			data.LastModified = types.StringNull()
+		}
+		diags.AddWarning("could not get user metadata",
+			fmt.Sprintf("create_date and last_modified are not updated, grant the metadata=read cap to read them: %s", err.Error()))
+		return diags
+	}
+
+	data.CreateDate = types.StringValue(meta.Data.CreateDate)
+	data.LastModified = types.StringValue(meta.Mtime)
+
+	return diags
+}
+
+// adoptUser applies the attributes of a user to be created to an existing user.
+// If s3 credentials are requested and the user has none, a key pair is generated.
+func (r *UserResource) adoptUser(ctx context.Context, rgwUser admin.User) (admin.User, error) {
+	generateKey := rgwUser.GenerateKey != nil && *rgwUser.GenerateKey
+	noKey := false
+	rgwUser.GenerateKey = &noKey
+	rgwUser.KeyType = ""
+
+	user, err := r.client.Admin.ModifyUser(ctx, rgwUser)
+	if err != nil {
+		return user, err
+	}
+
+	if generateKey && len(user.Keys) == 0 {
+		accessKey, err := generateAccessKey()
+		if err != nil {
+			return user, err
+		}
+
+		keys, err := r.client.Admin.CreateKey(ctx, admin.UserKeySpec{
+			UID:         user.ID,
+			KeyType:     "s3",
+			GenerateKey: &generateKey,
+			AccessKey:   accessKey,
+		})
+		if err != nil {
+			return user, err
+		}
+		if keys != nil {
+			user.Keys = *keys
+		}
 	}
 
-	return model, nil
+	return user, nil
 }
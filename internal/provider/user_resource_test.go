@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+func TestUpdateCapsTargeted(t *testing.T) {
+	var calls []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["caps"]; !ok {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.RawQuery)
+		}
+		calls = append(calls, r.Method+" "+r.URL.Query().Get("user-caps"))
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	err := client.updateCaps(context.Background(), "alice",
+		[]admin.UserCapSpec{{Type: "users", Perm: "read, write"}, {Type: "buckets", Perm: "*"}, {Type: "usage", Perm: "read"}},
+		[]admin.UserCapSpec{{Type: "users", Perm: "read"}, {Type: "metadata", Perm: "read"}, {Type: "usage", Perm: "read"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// caps are added before others are removed, unchanged caps are not touched
+	expected := []string{
+		"PUT metadata=read",
+		"DELETE buckets=read, write;users=write",
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("expected call %q, got %q", expected[i], calls[i])
+		}
+	}
+}
+
+func TestUpdateCapsUnchanged(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.RawQuery)
+	})
+
+	err := client.updateCaps(context.Background(), "alice",
+		[]admin.UserCapSpec{{Type: "users", Perm: "*"}},
+		[]admin.UserCapSpec{{Type: "users", Perm: "read, write"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestS3CredentialsFromApi(t *testing.T) {
+	keys := []admin.UserKeySpec{
+		{User: "alice", AccessKey: "KEYOFUSERKEY", SecretKey: "secret1"},
+		{User: "alice:swift", AccessKey: "KEYOFSUBUSER", SecretKey: "secret2"},
+		{User: "alice", AccessKey: "KEYOFUSER", SecretKey: "secret3"},
+	}
+
+	accessKey, secretKey := s3CredentialsFromApi(keys, "KEYOFUSER")
+	if accessKey.ValueString() != "KEYOFUSER" || secretKey.ValueString() != "secret3" {
+		t.Errorf("expected key KEYOFUSER, got %s", accessKey)
+	}
+
+	// a key rotated outside of terraform is dropped, other keys of the user
+	// like the ones of rgw_user_key resources are not taken over
+	accessKey, secretKey = s3CredentialsFromApi(keys, "ROTATEDKEY")
+	if !accessKey.IsNull() || !secretKey.IsNull() {
+		t.Errorf("expected no key after rotation, got %s", accessKey)
+	}
+	accessKey, _ = s3CredentialsFromApi(keys, "")
+	if !accessKey.IsNull() {
+		t.Errorf("expected no key without key in state, got %s", accessKey)
+	}
+}
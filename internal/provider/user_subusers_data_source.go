@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = &UserSubusersDataSource{}
+
+func NewUserSubusersDataSource() datasource.DataSource {
+	return &UserSubusersDataSource{}
+}
+
+type UserSubusersDataSource struct {
+	client *RgwClient
+}
+
+type UserSubusersDataSourceModel struct {
+	Id       types.String           `tfsdk:"id"`
+	UserId   types.String           `tfsdk:"user_id"`
+	Subusers []UserSubuserDataModel `tfsdk:"subusers"`
+}
+
+type UserSubuserDataModel struct {
+	Id          types.String `tfsdk:"id"`
+	Subuser     types.String `tfsdk:"subuser"`
+	Permissions types.String `tfsdk:"permissions"`
+}
+
+func (d *UserSubusersDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_subusers"
+}
+
+func (d *UserSubusersDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Existing subusers of a Ceph RGW User, including subusers not managed by `rgw_subuser`.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"user_id": schema.StringAttribute{
+				MarkdownDescription: "The full user ID (`tenant$username` or `username`).",
+				Required:            true,
+			},
+			"subusers": schema.ListNestedAttribute{
+				MarkdownDescription: "The subusers of the user",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "The full subuser ID (`<user_id>:<subuser>`), comparable to `rgw_subuser.id`",
+							Computed:            true,
+						},
+						"subuser": schema.StringAttribute{
+							MarkdownDescription: "The name of the subuser without the user ID prefix",
+							Computed:            true,
+						},
+						"permissions": schema.StringAttribute{
+							MarkdownDescription: "The access level in the format of `rgw_subuser.access`: `read`, `write`, `readwrite`, `full` or empty",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *UserSubusersDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "data.rgw_user_subusers")...)
+}
+
+func (d *UserSubusersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *UserSubusersDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// get user
+	user, err := d.client.Admin.GetUser(ctx, admin.User{ID: data.UserId.ValueString()})
+	if err != nil {
+		resp.Diagnostics.AddError("could not get user", err.Error())
+		return
+	}
+
+	data.Id = data.UserId
+
+	data.Subusers = make([]UserSubuserDataModel, len(user.Subusers))
+	for i, s := range user.Subusers {
+		access, ok := subuserAccessFromReply[s.Access]
+		if !ok {
+			access = s.Access
+		}
+		data.Subusers[i].Id = types.StringValue(s.Name)
+		data.Subusers[i].Subuser = types.StringValue(strings.TrimPrefix(s.Name, data.UserId.ValueString()+":"))
+		data.Subusers[i].Permissions = types.StringValue(string(access))
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = &UsersDataSource{}
+
+func NewUsersDataSource() datasource.DataSource {
+	return &UsersDataSource{}
+}
+
+type UsersDataSource struct {
+	client *RgwClient
+}
+
+type UsersDataSourceModel struct {
+	Id       types.String         `tfsdk:"id"`
+	Tenant   types.String         `tfsdk:"tenant"`
+	UidGlob  types.String         `tfsdk:"uid_glob"`
+	UidRegex types.String         `tfsdk:"uid_regex"`
+	Ids      []types.String       `tfsdk:"ids"`
+	Users    []UsersDataUserModel `tfsdk:"users"`
+}
+
+type UsersDataUserModel struct {
+	Id       types.String `tfsdk:"id"`
+	Tenant   types.String `tfsdk:"tenant"`
+	Username types.String `tfsdk:"username"`
+}
+
+func (d *UsersDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_users"
+}
+
+func (d *UsersDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the IDs of existing Ceph RGW Users, e.g. to generate `import` blocks for existing users.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "Only list users of this tenant. Use `\"\"` for the default tenant. All tenants are listed if not set.",
+				Optional:            true,
+			},
+			"uid_glob": schema.StringAttribute{
+				MarkdownDescription: "Only list users whose username (the user ID without tenant) matches this glob, e.g. `legacy-*`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("uid_regex")),
+				},
+			},
+			"uid_regex": schema.StringAttribute{
+				MarkdownDescription: "Only list users whose username (the user ID without tenant) matches this regular expression",
+				Optional:            true,
+			},
+			"ids": schema.ListAttribute{
+				MarkdownDescription: "The sorted full user IDs (`tenant$username` or `username`) of the matching users, usable as `id` of `import` blocks",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"users": schema.ListNestedAttribute{
+				MarkdownDescription: "The matching users, sorted by ID",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "The full user ID, usable as import ID of `rgw_user`",
+							Computed:            true,
+						},
+						"tenant": schema.StringAttribute{
+							MarkdownDescription: "The tenant of the user, empty for the default tenant",
+							Computed:            true,
+						},
+						"username": schema.StringAttribute{
+							MarkdownDescription: "The user ID without tenant",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *UsersDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*RgwClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *RgwClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+
+	// check caps of the provider credentials in strict mode
+	resp.Diagnostics.Append(client.checkRequiredCaps(ctx, "data.rgw_users")...)
+}
+
+func (d *UsersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Read Terraform configuration data into the model
+	var data *UsersDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// check the filters before listing all users
+	if !data.UidGlob.IsNull() {
+		if _, err := filepath.Match(data.UidGlob.ValueString(), ""); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("uid_glob"), "invalid glob", err.Error())
+			return
+		}
+	}
+	var uidRegex *regexp.Regexp
+	if !data.UidRegex.IsNull() {
+		var err error
+		uidRegex, err = regexp.Compile(data.UidRegex.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("uid_regex"), "invalid regular expression", err.Error())
+			return
+		}
+	}
+
+	users, err := d.client.Admin.GetUsers(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("could not list users", err.Error())
+		return
+	}
+
+	data.Users = []UsersDataUserModel{}
+	if users != nil {
+		sort.Strings(*users)
+		for _, userId := range *users {
+			// split user id into tenant and username
+			tenant, username := "", userId
+			if splittedId := strings.SplitN(userId, "$", 2); len(splittedId) == 2 {
+				tenant, username = splittedId[0], splittedId[1]
+			}
+
+			if !data.Tenant.IsNull() && tenant != data.Tenant.ValueString() {
+				continue
+			}
+			if !data.UidGlob.IsNull() {
+				if ok, _ := filepath.Match(data.UidGlob.ValueString(), username); !ok {
+					continue
+				}
+			}
+			if uidRegex != nil && !uidRegex.MatchString(username) {
+				continue
+			}
+
+			data.Users = append(data.Users, UsersDataUserModel{
+				Id:       types.StringValue(userId),
+				Tenant:   types.StringValue(tenant),
+				Username: types.StringValue(username),
+			})
+		}
+	}
+
+	data.Id = types.StringValue(fmt.Sprintf("%s/%s/%s", data.Tenant.ValueString(), data.UidGlob.ValueString(), data.UidRegex.ValueString()))
+	data.Ids = make([]types.String, len(data.Users))
+	for i, u := range data.Users {
+		data.Ids[i] = u.Id
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -41,6 +41,9 @@ func main() {
 
 	err := providerserver.Serve(context.Background(), provider.New(version), opts)
 
+	// Terraform shut the provider down, write the metrics of the operation
+	provider.WriteMetrics()
+
 	if err != nil {
 		log.Fatal(err.Error())
 	}